@@ -1,31 +1,307 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/go-plugin"
+	"github.com/shayne/hwinfo-streamdeck/internal/backend"
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/capture"
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/csvlog"
 	hwinfoplugin "github.com/shayne/hwinfo-streamdeck/internal/hwinfo/plugin"
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/synth"
+	"github.com/shayne/hwinfo-streamdeck/internal/hwmon"
+	"github.com/shayne/hwinfo-streamdeck/internal/lhm"
+	"github.com/shayne/hwinfo-streamdeck/internal/procfs"
+	"github.com/shayne/hwinfo-streamdeck/internal/prometheus"
 	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
 )
 
-func main() {
-	service := hwinfoplugin.StartService()
+// streamFromEnv picks the shared memory source:
+//
+//	HWINFO_SYNTH=1 serves synthetic demo sensors instead of reading HWiNFO,
+//	HWINFO_REPLAY=file.hwcap replays a capture instead of reading HWiNFO,
+//	HWINFO_REPLAY=file.csv replays a HWiNFO sensor log the same way,
+//	HWINFO_REPLAY_SPEED=2 replays at twice the original speed,
+//	HWINFO_RECORD=file.hwcap records everything read to a capture,
+//	HWINFO_POLL_INTERVAL=250ms how often shared memory is read,
+//	HWINFO_SKIP_UNCHANGED=1 only deliver snapshots HWiNFO has updated
+func streamFromEnv(ctx context.Context) (<-chan hwinfo.Result, error) {
+	var opts hwinfo.StreamOptions
+	interval, err := intervalFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	opts.Interval = interval
+	if v := os.Getenv("HWINFO_SKIP_UNCHANGED"); v != "" {
+		skip, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, err
+		}
+		opts.SkipUnchanged = skip
+	}
+
+	if v := os.Getenv("HWINFO_SYNTH"); v != "" {
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, err
+		}
+		if on {
+			return synth.Stream(ctx, opts, synth.NewSource(synth.Demo())), nil
+		}
+	}
+
+	if path := os.Getenv("HWINFO_REPLAY"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		speed := 1.
+		if v := os.Getenv("HWINFO_REPLAY_SPEED"); v != "" {
+			speed, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			l, err := csvlog.ReadLog(f)
+			if err != nil {
+				return nil, err
+			}
+			return l.Replay(ctx, speed), nil
+		}
+		r, err := capture.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		return capture.Replay(ctx, r, speed), nil
+	}
+
+	ch := hwinfo.StreamSharedMem(ctx, opts)
+	if path := os.Getenv("HWINFO_RECORD"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		w, err := capture.NewWriter(f)
+		if err != nil {
+			return nil, err
+		}
+		ch = capture.Record(ch, w)
+	}
+	return ch, nil
+}
+
+// intervalFromEnv HWINFO_POLL_INTERVAL, hwinfo.DefaultInterval when unset
+func intervalFromEnv() (time.Duration, error) {
+	v := os.Getenv("HWINFO_POLL_INTERVAL")
+	if v == "" {
+		return hwinfo.DefaultInterval, nil
+	}
+	return time.ParseDuration(v)
+}
+
+// historyFromEnv HWINFO_HISTORY_DEPTH samples per reading and
+// HWINFO_HISTORY_MAX_BYTES, the defaults of each when unset
+func historyFromEnv() (hwinfoplugin.HistoryOptions, error) {
+	opts := hwinfoplugin.DefaultHistoryOptions
+	for env, v := range map[string]*int{
+		"HWINFO_HISTORY_DEPTH":     &opts.Depth,
+		"HWINFO_HISTORY_MAX_BYTES": &opts.MaxBytes,
+	} {
+		s := os.Getenv(env)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return opts, fmt.Errorf("invalid %s %q", env, s)
+		}
+		*v = n
+	}
+	return opts, nil
+}
+
+// startHWiNFO serves HWiNFO shared memory, done is closed
+// once the stream closed after ctx is done
+func startHWiNFO(ctx context.Context) (hwsensorsservice.HardwareService, <-chan struct{}, error) {
+	hopts, err := historyFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	ch, err := streamFromEnv(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open shared memory stream: %w", err)
+	}
+	service := hwinfoplugin.NewService(ch)
+	service.KeepHistory(hopts)
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		for {
 			err := service.Recv()
+			if errors.Is(err, io.EOF) {
+				log.Println("service stream closed")
+				return
+			}
 			if err != nil {
 				log.Printf("service recv failed: %v\n", err)
 			}
 		}
 	}()
+	return &hwinfoplugin.Plugin{Service: service}, done, nil
+}
+
+// startBackend serves the backend picked by HWINFO_BACKEND:
+//
+//	hwinfo (default) HWiNFO shared memory, see streamFromEnv,
+//	hwmon Linux sysfs sensors, HWINFO_HWMON_ROOT overrides /sys/class/hwmon,
+//	proc Linux system load, HWINFO_PROC_ROOT and HWINFO_SYS_ROOT override /proc and /sys,
+//	lhm LibreHardwareMonitor web server, HWINFO_LHM_URL overrides http://localhost:8085/data.json,
+//	csv tails the HWiNFO sensor log at HWINFO_CSV_LOG,
+//	prometheus scrapes the exporters configured in the JSON file HWINFO_PROMETHEUS_CONFIG
+func startBackend(ctx context.Context) (hwsensorsservice.HardwareService, <-chan struct{}, error) {
+	name := os.Getenv("HWINFO_BACKEND")
+	if name == "" || name == "hwinfo" {
+		return startHWiNFO(ctx)
+	}
+
+	interval, err := intervalFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	var service *backend.Service
+	switch name {
+	case "hwmon":
+		service = hwmon.NewService(os.Getenv("HWINFO_HWMON_ROOT"))
+	case "proc":
+		service = procfs.NewService(os.Getenv("HWINFO_PROC_ROOT"), os.Getenv("HWINFO_SYS_ROOT"))
+	case "lhm":
+		service = lhm.NewService(os.Getenv("HWINFO_LHM_URL"))
+	case "csv":
+		path := os.Getenv("HWINFO_CSV_LOG")
+		if path == "" {
+			return nil, nil, fmt.Errorf("csv backend needs HWINFO_CSV_LOG")
+		}
+		service = csvlog.NewService(path)
+	case "prometheus":
+		path := os.Getenv("HWINFO_PROMETHEUS_CONFIG")
+		if path == "" {
+			return nil, nil, fmt.Errorf("prometheus backend needs HWINFO_PROMETHEUS_CONFIG")
+		}
+		cfg, err := prometheus.LoadConfig(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if service, err = prometheus.NewService(cfg); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown backend %q", name)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Run(ctx, interval)
+	}()
+	return service, done, nil
+}
+
+var listen = flag.String("listen", "", "Serve remote clients on this address, e.g. :"+hwsensorsservice.DefaultPort+", instead of a parent process")
+var tlsCert = flag.String("tls-cert", "hwinfo-plugin.crt", "TLS certificate, a self-signed one is created along with the key when both are missing")
+var tlsKey = flag.String("tls-key", "hwinfo-plugin.key", "TLS private key")
+var tokenFile = flag.String("token-file", "", "File holding the token remote clients must send, HWINFO_TOKEN when unset")
+var plaintext = flag.Bool("insecure", false, "Serve remote clients without TLS, the token is sent in the clear")
+
+// serveRemote serves impl on -listen until ctx is done
+func serveRemote(ctx context.Context, impl hwsensorsservice.HardwareService) error {
+	token := os.Getenv("HWINFO_TOKEN")
+	if *tokenFile != "" {
+		b, err := os.ReadFile(*tokenFile)
+		if err != nil {
+			return err
+		}
+		token = strings.TrimSpace(string(b))
+	}
+	if token == "" {
+		return fmt.Errorf("remote clients need a token, set -token-file or HWINFO_TOKEN")
+	}
+
+	var tlsConfig *tls.Config
+	if !*plaintext {
+		hosts := []string{"localhost", "127.0.0.1"}
+		if name, err := os.Hostname(); err == nil {
+			hosts = append(hosts, name)
+		}
+		cert, err := hwsensorsservice.LoadOrCreateCertificate(*tlsCert, *tlsKey, hosts)
+		if err != nil {
+			return fmt.Errorf("TLS certificate: %w", err)
+		}
+		log.Printf("certificate fingerprint %s\n", hwsensorsservice.CertFingerprint(cert))
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+
+	lis, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return err
+	}
+	srv := hwsensorsservice.NewServer(impl, tlsConfig, token)
+	go func() {
+		<-ctx.Done()
+		srv.GracefulStop()
+	}()
+	log.Printf("serving on %s\n", lis.Addr())
+	return srv.Serve(lis)
+}
+
+func main() {
+	flag.Parse()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if *listen != "" {
+		// go-plugin deals with interrupts meant for the host itself
+		ctx, cancel = signal.NotifyContext(ctx, os.Interrupt)
+		defer cancel()
+	}
+
+	impl, done, err := startBackend(ctx)
+	if err != nil {
+		log.Fatalf("failed to start backend: %v", err)
+	}
+
+	if *listen != "" {
+		if err := serveRemote(ctx, impl); err != nil {
+			log.Fatalf("serve: %v", err)
+		}
+		cancel()
+		<-done
+		return
+	}
 
 	plugin.Serve(&plugin.ServeConfig{
 		HandshakeConfig: hwsensorsservice.Handshake,
-		Plugins: map[string]plugin.Plugin{
-			"hwinfoplugin": &hwsensorsservice.HardwareServicePlugin{Impl: &hwinfoplugin.Plugin{Service: service}},
-		},
+		// hosts predating versioned plugins get version 1
+		VersionedPlugins: hwsensorsservice.VersionedPluginsFor(impl),
 
 		// A non-nil value here enables gRPC serving for this plugin...
 		GRPCServer: plugin.DefaultGRPCServer,
 	})
+
+	// host disconnected, stop polling and let the backend drain
+	cancel()
+	<-done
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/capture"
+)
+
+var out = flag.String("o", "hwinfo.hwcap", "Capture file to write")
+var duration = flag.Duration("d", time.Minute, "How long to record")
+var interval = flag.Duration("i", hwinfo.DefaultInterval, "How often to read shared memory")
+
+func main() {
+	flag.Parse()
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("Create capture: %v", err)
+	}
+	defer f.Close()
+
+	w, err := capture.NewWriter(f)
+	if err != nil {
+		log.Fatalf("NewWriter: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+
+	frames := 0
+	for r := range hwinfo.StreamSharedMem(ctx, hwinfo.StreamOptions{Interval: *interval, SkipUnchanged: true}) {
+		if r.Err != nil {
+			log.Printf("ReadSharedMem: %v\n", r.Err)
+			continue
+		}
+		err := w.WriteFrame(time.Now(), r.Shmem)
+		r.Shmem.Release()
+		if err != nil {
+			log.Fatalf("WriteFrame: %v", err)
+		}
+		frames++
+	}
+	log.Printf("Wrote %d frames to %s\n", frames, *out)
+}
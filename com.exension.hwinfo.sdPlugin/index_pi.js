@@ -104,6 +104,8 @@ function connectElgatoStreamDeckSocket(inPort, inUUID, inRegisterEvent, inInfo,
       if (settings.valueTextColor !== "") {
         document.querySelector("#valuetext").value = settings.valueTextColor;
       }
+      document.querySelector("#labelAsTitle").checked =
+        settings.labelAsTitle === true;
       if (settings.titleFontSize !== "") {
         document.querySelector("#titleFontSize input").value =
           settings.titleFontSize || 10.5;
@@ -139,10 +141,15 @@ function addSensors(el, sensors, settings) {
     option.selected = true;
   }
   el.add(option);
-  var sortByName = sortBy("name");
+  // show names as renamed in HWiNFO, original name on hover
+  sensors.forEach((s) => {
+    s.displayName = s.nameUser || s.name;
+  });
+  var sortByName = sortBy("displayName");
   sensors.sort(sortByName).forEach((s) => {
     var option = document.createElement("option");
-    option.text = s.name;
+    option.text = s.displayName;
+    option.title = s.name;
     option.value = s.uid;
     if (settings.isValid === true && settings.sensorUid === s.uid) {
       option.selected = true;
@@ -171,7 +178,11 @@ function addReadings(el, readings, settings) {
   }
   el.add(option);
 
-  var sortByLabel = sortBy("label");
+  // show labels as renamed in HWiNFO, original label on hover
+  readings.forEach((r) => {
+    r.displayLabel = r.labelUser || r.label;
+  });
+  var sortByLabel = sortBy("displayLabel");
   var maxL = 0;
   readings.sort(sortByLabel).forEach((r) => {
     var l = r.prefix.length;
@@ -186,7 +197,8 @@ function addReadings(el, readings, settings) {
     for (i = 0; i < maxL - r.prefix.length; ++i) {
       spaces += "&nbsp;";
     }
-    option.innerHTML = `${r.prefix}${spaces}${r.label}`;
+    option.innerHTML = `${r.prefix}${spaces}${r.displayLabel}`;
+    option.title = r.label;
     option.value = r.id;
     if (settings.isValid === true && settings.readingId === r.id) {
       option.selected = true;
@@ -2,8 +2,11 @@ package hwinfostreamdeckplugin
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
+
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
 )
 
 type actionManager struct {
@@ -15,17 +18,21 @@ func newActionManager() *actionManager {
 	return &actionManager{actions: make(map[string]*actionData)}
 }
 
-func (tm *actionManager) Run(updateTiles func(*actionData)) {
+// Run calls updateTiles with all valid actions once a second. Their
+// settings are shared, changes are made with updateSettings.
+func (tm *actionManager) Run(updateTiles func([]*actionData)) {
 	go func() {
 		ticker := time.NewTicker(time.Second)
 		for range ticker.C {
 			tm.mux.RLock()
+			var actions []*actionData
 			for _, data := range tm.actions {
 				if data.settings.IsValid {
-					updateTiles(data)
+					actions = append(actions, data)
 				}
 			}
 			tm.mux.RUnlock()
+			updateTiles(actions)
 		}
 	}()
 }
@@ -52,3 +59,46 @@ func (tm *actionManager) getSettings(context string) (actionSettings, error) {
 	// return full copy of settings, not reference to stored settings
 	return *data.settings, nil
 }
+
+// updateSettings applies f to a copy of the settings of context and
+// stores the copy, settings are read without the write lock so they
+// are never changed in place. Returns the new settings, false when
+// the action is gone.
+func (tm *actionManager) updateSettings(context string, f func(*actionSettings)) (*actionSettings, bool) {
+	tm.mux.Lock()
+	defer tm.mux.Unlock()
+
+	data, ok := tm.actions[context]
+	if !ok {
+		return nil, false
+	}
+	settings := *data.settings
+	f(&settings)
+	tm.actions[context] = &actionData{data.action, context, &settings}
+	return &settings, true
+}
+
+// readingKeys the readings of all valid actions, sorted
+func (tm *actionManager) readingKeys() []hwsensorsservice.ReadingKey {
+	tm.mux.RLock()
+	seen := make(map[hwsensorsservice.ReadingKey]bool)
+	var keys []hwsensorsservice.ReadingKey
+	for _, data := range tm.actions {
+		if !data.settings.IsValid {
+			continue
+		}
+		k := hwsensorsservice.ReadingKey{SensorID: data.settings.SensorUID, ReadingID: data.settings.ReadingID}
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	tm.mux.RUnlock()
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].SensorID != keys[j].SensorID {
+			return keys[i].SensorID < keys[j].SensorID
+		}
+		return keys[i].ReadingID < keys[j].ReadingID
+	})
+	return keys
+}
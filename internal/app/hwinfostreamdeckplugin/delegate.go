@@ -1,6 +1,7 @@
 package hwinfostreamdeckplugin
 
 import (
+	"context"
 	"encoding/json"
 	"image/color"
 	"log"
@@ -70,6 +71,8 @@ func (p *Plugin) OnWillAppear(event *streamdeck.EvWillAppear) {
 	g.SetLabelFontSize(0, tfSize)
 	g.SetLabel(1, "", 44, vtColor)
 	g.SetLabelFontSize(1, vfSize)
+	// before the tile updates on its own
+	p.prefillGraph(g, &settings)
 	p.graphs[event.Context] = g
 	p.am.SetAction(event.Action, event.Context, &settings)
 }
@@ -85,14 +88,13 @@ func (p *Plugin) OnWillDisappear(event *streamdeck.EvWillDisappear) {
 	p.am.RemoveAction(event.Context)
 }
 
-// OnApplicationDidLaunch event
+// OnApplicationDidLaunch event, tiles follow the Status of their
+// source instead, which covers remote and other backends as well
 func (p *Plugin) OnApplicationDidLaunch(event *streamdeck.EvApplication) {
-	p.appLaunched = true
 }
 
 // OnApplicationDidTerminate event
 func (p *Plugin) OnApplicationDidTerminate(event *streamdeck.EvApplication) {
-	p.appLaunched = false
 }
 
 // OnTitleParametersDidChange event
@@ -129,7 +131,7 @@ func (p *Plugin) OnPropertyInspectorConnected(event *streamdeck.EvSendToPlugin)
 	if err != nil {
 		log.Println("OnPropertyInspectorConnected getSettings", err)
 	}
-	sensors, err := p.hw.Sensors()
+	sensors, err := p.hw.Sensors(context.Background())
 	if err != nil {
 		log.Println("OnPropertyInspectorConnected Sensors", err)
 		payload := evStatus{Error: true, Message: "HWiNFO Unavailable"}
@@ -148,7 +150,7 @@ func (p *Plugin) OnPropertyInspectorConnected(event *streamdeck.EvSendToPlugin)
 	}
 	evsensors := make([]*evSendSensorsPayloadSensor, 0, len(sensors))
 	for _, s := range sensors {
-		evsensors = append(evsensors, &evSendSensorsPayloadSensor{UID: s.ID(), Name: s.Name()})
+		evsensors = append(evsensors, &evSendSensorsPayloadSensor{UID: s.ID(), Name: s.Name(), NameUser: s.NameUser()})
 	}
 	payload := evSendSensorsPayload{Sensors: evsensors, Settings: &settings}
 	err = p.sd.SendToPropertyInspector(event.Action, event.Context, payload)
@@ -206,6 +208,11 @@ func (p *Plugin) OnSendToPlugin(event *streamdeck.EvSendToPlugin) {
 			if err != nil {
 				log.Println("handleColorChange", err)
 			}
+		case "labelAsTitle":
+			err := p.handleLabelAsTitle(event, &sdpi)
+			if err != nil {
+				log.Println("handleLabelAsTitle", err)
+			}
 		case "titleFontSize", "valueFontSize":
 			err := p.handleSetFontSize(event, sdpi.Key, &sdpi)
 			if err != nil {
@@ -1,6 +1,7 @@
 package hwinfostreamdeckplugin
 
 import (
+	"context"
 	"fmt"
 	"image/color"
 	"strconv"
@@ -11,13 +12,13 @@ import (
 
 func (p *Plugin) handleSensorSelect(event *streamdeck.EvSendToPlugin, sdpi *evSdpiCollection) error {
 	sensorid := sdpi.Value
-	readings, err := p.hw.ReadingsForSensorID(sensorid)
+	readings, err := p.hw.ReadingsForSensorID(context.Background(), sensorid)
 	if err != nil {
 		return fmt.Errorf("handleSensorSelect ReadingsBySensor failed: %v", err)
 	}
 	evreadings := []*evSendReadingsPayloadReading{}
 	for _, r := range readings {
-		evreadings = append(evreadings, &evSendReadingsPayloadReading{ID: r.ID(), Label: r.Label(), Prefix: r.Unit()})
+		evreadings = append(evreadings, &evSendReadingsPayloadReading{ID: r.ID(), Label: r.Label(), LabelUser: r.LabelUser(), Prefix: r.Unit()})
 	}
 	settings, err := p.am.getSettings(event.Context)
 	if err != nil {
@@ -29,6 +30,7 @@ func (p *Plugin) handleSensorSelect(event *streamdeck.EvSendToPlugin, sdpi *evSd
 	if settings.SensorUID != sensorid {
 		settings.SensorUID = sensorid
 		settings.ReadingID = 0
+		settings.Fingerprint = nil
 		settings.IsValid = false
 	}
 	payload := evSendReadingsPayload{Readings: evreadings, Settings: &settings}
@@ -80,10 +82,15 @@ func (p *Plugin) handleReadingSelect(event *streamdeck.EvSendToPlugin, sdpi *evS
 	settings.ReadingID = rid
 
 	// set default min/max
-	r, err := p.getReading(settings.SensorUID, settings.ReadingID)
+	r, err := p.getReading(context.Background(), settings.SensorUID, settings.ReadingID)
 	if err != nil {
 		return fmt.Errorf("handleReadingSelect getReading: %v", err)
 	}
+	fp, err := hwsensorsservice.NewResolver(p.hw).Fingerprint(context.Background(), settings.SensorUID, settings.ReadingID)
+	if err != nil {
+		return fmt.Errorf("handleReadingSelect Fingerprint: %v", err)
+	}
+	settings.Fingerprint = &fp
 
 	g, ok := p.graphs[event.Context]
 	if !ok {
@@ -180,6 +187,25 @@ func (p *Plugin) handleDivisor(event *streamdeck.EvSendToPlugin, sdpi *evSdpiCol
 	return nil
 }
 
+func (p *Plugin) handleLabelAsTitle(event *streamdeck.EvSendToPlugin, sdpi *evSdpiCollection) error {
+	settings, err := p.am.getSettings(event.Context)
+	if err != nil {
+		return fmt.Errorf("handleLabelAsTitle getSettings: %v", err)
+	}
+	settings.LabelAsTitle = sdpi.Checked
+	if !settings.LabelAsTitle && settings.Title == "" {
+		if g, ok := p.graphs[event.Context]; ok {
+			g.SetLabelText(0, "")
+		}
+	}
+	err = p.sd.SetSettings(event.Context, &settings)
+	if err != nil {
+		return fmt.Errorf("handleLabelAsTitle SetSettings: %v", err)
+	}
+	p.am.SetAction(event.Action, event.Context, &settings)
+	return nil
+}
+
 const (
 	hexFormat      = "#%02x%02x%02x"
 	hexShortFormat = "#%1x%1x%1x"
@@ -0,0 +1,63 @@
+package hwinfostreamdeckplugin
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/pkg/graph"
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+// graphTick time between two points of a tile graph, see actionManager.Run
+const graphTick = time.Second
+
+// graphPoints resamples samples, oldest first, to the n ticks up to end,
+// each the newest value at or before its tick. Ticks before the first
+// sample are left out.
+func graphPoints(samples []hwsensorsservice.Sample, end time.Time, n int, tick time.Duration) []float64 {
+	var points []float64
+	i := 0
+	var last *hwsensorsservice.Sample
+	for t := end.Add(-time.Duration(n-1) * tick); !t.After(end); t = t.Add(tick) {
+		for i < len(samples) && !samples[i].Time.After(t) {
+			last = &samples[i]
+			i++
+		}
+		if last != nil {
+			points = append(points, last.Value)
+		}
+	}
+	return points
+}
+
+// prefillGraph draws the recent history of the tile's reading, so a
+// tile appearing again does not start with an empty graph
+func (p *Plugin) prefillGraph(g *graph.Graph, s *actionSettings) {
+	if !s.IsValid {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tickTimeout)
+	defer cancel()
+
+	end := time.Now()
+	key := hwsensorsservice.ReadingKey{SensorID: s.SensorUID, ReadingID: s.ReadingID}
+	samples, err := hwsensorsservice.History(ctx, p.hw, key, end.Add(-tileWidth*graphTick), 0)
+	if err != nil {
+		if !errors.Is(err, hwsensorsservice.ErrNoHistory) {
+			log.Printf("prefillGraph History: %v\n", err)
+		}
+		return
+	}
+	fdiv := 1.
+	if s.Divisor != "" {
+		if fdiv, err = strconv.ParseFloat(s.Divisor, 64); err != nil {
+			return
+		}
+	}
+	for _, v := range graphPoints(samples, end, tileWidth, graphTick) {
+		g.Update(v / fdiv)
+	}
+}
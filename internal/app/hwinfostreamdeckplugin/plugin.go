@@ -1,11 +1,18 @@
 package hwinfostreamdeckplugin
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"log"
+	"os"
 	"os/exec"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/go-plugin"
@@ -17,27 +24,93 @@ import (
 
 // Plugin handles information between HWiNFO and Stream Deck
 type Plugin struct {
-	c      *plugin.Client
-	peg    winpeg.ProcessExitGroup
-	hw     hwsensorsservice.HardwareService
-	sd     *streamdeck.StreamDeck
-	am     *actionManager
-	graphs map[string]*graph.Graph
+	sources []*source
+	peg     winpeg.ProcessExitGroup
+	hw      *hwsensorsservice.Composite
+	sd      *streamdeck.StreamDeck
+	am      *actionManager
+	cache   *readingCache
+	retries *retryLimiter
+	graphs  map[string]*graph.Graph
+}
+
+// source a hwinfo-plugin process serving one hardware source, or a
+// remote hwinfo-plugin -listen server when Address is set. The local
+// HWiNFO is the default source with an empty name.
+type source struct {
+	Name string            `json:"name"`
+	Env  map[string]string `json:"env"`
+
+	Address         string `json:"address"`
+	Token           string `json:"token"`
+	CertFingerprint string `json:"certFingerprint"`
 
-	appLaunched bool
+	c *plugin.Client
 }
 
-func (p *Plugin) startClient() error {
+// sourcesFile optional list of additional sources, e.g.
+//
+//	{"sources": [
+//		{"name": "lab1", "env": {"HWINFO_BACKEND": "prometheus", "HWINFO_PROMETHEUS_CONFIG": "lab1.json"}},
+//		{"name": "render", "address": "render-box:7878", "token": "...", "certFingerprint": "0d24..."}
+//	]}
+const sourcesFile = "./sources.json"
+
+func loadSources() ([]*source, error) {
+	sources := []*source{{}}
+	b, err := ioutil.ReadFile(sourcesFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return sources, nil
+	}
+	if err != nil {
+		return sources, err
+	}
+	var cfg struct {
+		Sources []*source `json:"sources"`
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return sources, fmt.Errorf("%s: %v", sourcesFile, err)
+	}
+	for _, s := range cfg.Sources {
+		if s.Name == "" || strings.ContainsAny(s.Name, hwsensorsservice.SourceSeparator+hwsensorsservice.SchemeSeparator) {
+			return sources, fmt.Errorf("%s: invalid source name %q", sourcesFile, s.Name)
+		}
+		sources = append(sources, s)
+	}
+	return sources, nil
+}
+
+// dialRemote connects to a remote source, gRPC reconnects by itself
+// so it is only dialed once
+func (p *Plugin) dialRemote(s *source) error {
+	c, _, err := hwsensorsservice.Dial(s.Address, hwsensorsservice.DialOptions{
+		Token:           s.Token,
+		CertFingerprint: s.CertFingerprint,
+	})
+	if err != nil {
+		return err
+	}
+	return p.hw.Add(s.Name, c)
+}
+
+func (p *Plugin) startClient(s *source) error {
 	cmd := exec.Command("./hwinfo-plugin.exe")
+	if len(s.Env) > 0 {
+		cmd.Env = os.Environ()
+		for k, v := range s.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+	}
 
 	// We're a host. Start by launching the plugin process.
 	client := plugin.NewClient(&plugin.ClientConfig{
 		HandshakeConfig:  hwsensorsservice.Handshake,
-		Plugins:          hwsensorsservice.PluginMap,
+		VersionedPlugins: hwsensorsservice.VersionedPlugins,
 		Cmd:              cmd,
 		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
 		AutoMTLS:         true,
 	})
+	s.c = client
 
 	// Connect via RPC
 	rpcClient, err := client.Client()
@@ -45,37 +118,53 @@ func (p *Plugin) startClient() error {
 		return err
 	}
 
-	g, err := winpeg.NewProcessExitGroup()
-	if err != nil {
-		return err
-	}
-
-	if err := g.AddProcess(cmd.Process); err != nil {
+	if err := p.peg.AddProcess(cmd.Process); err != nil {
 		return err
 	}
 
 	// Request the plugin
-	raw, err := rpcClient.Dispense("hwinfoplugin")
+	raw, err := rpcClient.Dispense(hwsensorsservice.PluginName)
 	if err != nil {
 		return err
 	}
+	if v := client.NegotiatedVersion(); v < hwsensorsservice.ProtocolVersion {
+		log.Printf("source %q: hwinfo-plugin speaks protocol version %d of %d, update it for all features\n",
+			s.Name, v, hwsensorsservice.ProtocolVersion)
+	}
 
-	p.c = client
-	p.peg = g
-	p.hw = raw.(hwsensorsservice.HardwareService)
-
-	return nil
+	return p.hw.Add(s.Name, raw.(hwsensorsservice.HardwareService))
 }
 
 // NewPlugin creates an instance and initializes the plugin
 func NewPlugin(port, uuid, event, info string) (*Plugin, error) {
 	// We don't want to see the plugin logs.
 	// log.SetOutput(ioutil.Discard)
+	g, err := winpeg.NewProcessExitGroup()
+	if err != nil {
+		return nil, err
+	}
+	sources, err := loadSources()
+	if err != nil {
+		log.Printf("loadSources: %v\n", err)
+	}
 	p := &Plugin{
-		am:     newActionManager(),
-		graphs: make(map[string]*graph.Graph),
+		sources: sources,
+		peg:     g,
+		hw:      hwsensorsservice.NewComposite(),
+		am:      newActionManager(),
+		cache:   newReadingCache(),
+		retries: newRetryLimiter(rebindBackoff),
+		graphs:  make(map[string]*graph.Graph),
+	}
+	for _, s := range p.sources {
+		start := p.startClient
+		if s.Address != "" {
+			start = p.dialRemote
+		}
+		if err := start(s); err != nil {
+			log.Printf("start source %q: %v\n", s.Name, err)
+		}
 	}
-	p.startClient()
 	p.sd = streamdeck.NewStreamDeck(port, uuid, event, info)
 	return p, nil
 }
@@ -83,17 +172,26 @@ func NewPlugin(port, uuid, event, info string) (*Plugin, error) {
 // RunForever starts the plugin and waits for events, indefinitely
 func (p *Plugin) RunForever() error {
 	defer func() {
-		p.c.Kill()
+		for _, s := range p.sources {
+			if s.c != nil {
+				s.c.Kill()
+			}
+		}
 		p.peg.Dispose()
 	}()
 
 	p.sd.SetDelegate(p)
 	p.am.Run(p.updateTiles)
+	go p.subscribe()
 
 	go func() {
 		for {
-			if p.c.Exited() {
-				p.startClient()
+			for _, s := range p.sources {
+				if s.c != nil && s.c.Exited() {
+					if err := p.startClient(s); err != nil {
+						log.Printf("startClient %q: %v\n", s.Name, err)
+					}
+				}
 			}
 			time.Sleep(1 * time.Second)
 		}
@@ -108,17 +206,123 @@ func (p *Plugin) RunForever() error {
 	return nil
 }
 
-func (p *Plugin) getReading(suid string, rid int32) (hwsensorsservice.Reading, error) {
-	rbs, err := p.hw.ReadingsForSensorID(suid)
+// readingBatch readings looked up in one call
+type readingBatch map[hwsensorsservice.ReadingKey]hwsensorsservice.ReadingResult
+
+// lookupReadings reads the keys missing from the cache in one call
+func (p *Plugin) lookupReadings(ctx context.Context, keys []hwsensorsservice.ReadingKey) (readingBatch, error) {
+	batch := make(readingBatch)
+	var missing []hwsensorsservice.ReadingKey
+	for _, k := range keys {
+		if _, ok := p.cache.get(k); !ok {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) == 0 {
+		return batch, nil
+	}
+	results, err := p.hw.ReadingsByKeys(ctx, missing)
 	if err != nil {
-		return nil, fmt.Errorf("getReading ReadingsBySensor failed: %v", err)
+		return batch, fmt.Errorf("lookupReadings ReadingsByKeys failed: %v", err)
 	}
-	for _, r := range rbs {
-		if r.ID() == rid {
-			return r, nil
-		}
+	for _, res := range results {
+		batch[res.Key] = res
+	}
+	return batch, nil
+}
+
+// reading the pushed reading, or the one looked up in batch
+// for tiles not subscribed yet
+func (p *Plugin) reading(batch readingBatch, suid string, rid int32) (hwsensorsservice.Reading, error) {
+	k := hwsensorsservice.ReadingKey{SensorID: suid, ReadingID: rid}
+	if r, ok := p.cache.get(k); ok {
+		return r, nil
+	}
+	res, ok := batch[k]
+	if !ok {
+		return nil, fmt.Errorf("reading not looked up: %s", k)
 	}
-	return nil, fmt.Errorf("ReadingID does not exist: %s", suid)
+	return res.Reading, res.Err
+}
+
+func (p *Plugin) getReading(ctx context.Context, suid string, rid int32) (hwsensorsservice.Reading, error) {
+	batch, err := p.lookupReadings(ctx, []hwsensorsservice.ReadingKey{{SensorID: suid, ReadingID: rid}})
+	if err != nil {
+		return nil, err
+	}
+	return p.reading(batch, suid, rid)
+}
+
+// rebindBackoff time between attempts to rebind or fingerprint a
+// tile, each attempt asks every source for its sensors
+const rebindBackoff = 30 * time.Second
+
+// retryLimiter spaces out retries of failed attempts per key
+type retryLimiter struct {
+	mu      sync.Mutex
+	backoff time.Duration
+	next    map[string]time.Time
+}
+
+func newRetryLimiter(backoff time.Duration) *retryLimiter {
+	return &retryLimiter{backoff: backoff, next: make(map[string]time.Time)}
+}
+
+// allow reports whether key may be attempted now
+func (l *retryLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return !time.Now().Before(l.next[key])
+}
+
+// done records the outcome of an attempt of key, failures hold
+// off the next attempt for the backoff
+func (l *retryLimiter) done(key string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err == nil {
+		delete(l.next, key)
+		return
+	}
+	l.next[key] = time.Now().Add(l.backoff)
+}
+
+// rebindReading finds the tile's reading by its fingerprint after the
+// saved sensor/reading IDs disappeared, e.g. HWiNFO renumbered sensor
+// instances after a driver update, and saves the new IDs
+func (p *Plugin) rebindReading(ctx context.Context, data *actionData) (hwsensorsservice.Reading, *actionData, error) {
+	fp := data.settings.Fingerprint
+	if fp == nil {
+		return nil, data, fmt.Errorf("rebindReading no fingerprint for context: %s", data.context)
+	}
+	m, err := hwsensorsservice.NewResolver(p.hw).Resolve(ctx, *fp)
+	if err != nil {
+		return nil, data, fmt.Errorf("rebindReading Resolve: %v", err)
+	}
+	log.Printf("Rebound %s/%s from %s:%d to %s:%d, confidence %.2f\n",
+		fp.SensorName, fp.Label, data.settings.SensorUID, data.settings.ReadingID,
+		m.Sensor.ID(), m.Reading.ID(), m.Confidence)
+
+	newfp := hwsensorsservice.NewFingerprint(m.Sensor, m.Reading)
+	data = p.saveSettings(data, func(s *actionSettings) {
+		s.SensorUID = newfp.SensorID
+		s.ReadingID = newfp.ReadingID
+		s.Fingerprint = &newfp
+	})
+	return m.Reading, data, nil
+}
+
+// saveSettings changes the settings of the tile with f and sends them
+// to the Stream Deck, returns the tile with the new settings
+func (p *Plugin) saveSettings(data *actionData, f func(*actionSettings)) *actionData {
+	settings, ok := p.am.updateSettings(data.context, f)
+	if !ok {
+		return data
+	}
+	if err := p.sd.SetSettings(data.context, settings); err != nil {
+		log.Printf("SetSettings: %v\n", err)
+	}
+	return &actionData{data.action, data.context, settings}
 }
 
 func (p *Plugin) applyDefaultFormat(v float64, t hwsensorsservice.ReadingType, u string) string {
@@ -145,9 +349,104 @@ func (p *Plugin) applyDefaultFormat(v float64, t hwsensorsservice.ReadingType, u
 	return "Bad Format"
 }
 
-func (p *Plugin) updateTiles(data *actionData) {
+// tickTimeout bounds the calls of a tick, a hung source must not
+// block the tiles while the action manager is locked
+const tickTimeout = time.Second
+
+// sourceStatus status of the source of a tile
+type sourceStatus struct {
+	name string
+	hwsensorsservice.Status
+	err error
+}
+
+func (s sourceStatus) available() bool {
+	return s.err == nil && s.State == hwsensorsservice.StateActive
+}
+
+// hwinfo the source is the local HWiNFO, whose tiles offer to launch it
+func (s sourceStatus) hwinfo() bool {
+	return s.Backend == hwsensorsservice.BackendHWiNFO || (s.name == "" && s.Backend == "")
+}
+
+// unsupported the source runs but cannot be decoded, launching
+// HWiNFO does not help
+func (s sourceStatus) unsupported() bool {
+	if s.err != nil {
+		return errors.Is(s.err, hwsensorsservice.ErrLayoutMismatch)
+	}
+	return s.Backend == hwsensorsservice.BackendHWiNFO && s.State == hwsensorsservice.StateUnknown && s.LastError != ""
+}
+
+// message shown in the property inspector while unavailable
+func (s sourceStatus) message() string {
+	name := s.name
+	if s.hwinfo() {
+		name = "HWiNFO"
+	} else if name == "" {
+		name = s.Backend
+	}
+	if s.unsupported() {
+		return name + " Unsupported"
+	}
+	return name + " Unavailable"
+}
+
+// tileStatus the status of the source of sensor id, looked up once
+// per source and tick
+func (p *Plugin) tileStatus(ctx context.Context, statuses map[string]sourceStatus, id string) sourceStatus {
+	name, err := p.hw.SourceOf(id)
+	if err != nil {
+		return sourceStatus{err: err}
+	}
+	st, ok := statuses[name]
+	if !ok {
+		st = sourceStatus{name: name}
+		st.Status, st.err = p.hw.SourceStatus(ctx, name)
+		statuses[name] = st
+	}
+	return st
+}
+
+func (p *Plugin) updateTiles(actions []*actionData) {
+	ctx, cancel := context.WithTimeout(context.Background(), tickTimeout)
+	defer cancel()
+
+	statuses := make(map[string]sourceStatus)
+	tileStatuses := make([]sourceStatus, len(actions))
+	// one lookup per tick for all tiles the subscription did not cover
+	var keys []hwsensorsservice.ReadingKey
+	for i, data := range actions {
+		tileStatuses[i] = p.tileStatus(ctx, statuses, data.settings.SensorUID)
+		if tileStatuses[i].available() {
+			keys = append(keys, hwsensorsservice.ReadingKey{SensorID: data.settings.SensorUID, ReadingID: data.settings.ReadingID})
+		}
+	}
+	batch, err := p.lookupReadings(ctx, keys)
+	if err != nil {
+		log.Printf("updateTiles: %v\n", err)
+	}
+	for i, data := range actions {
+		p.updateTile(ctx, batch, tileStatuses[i], data)
+	}
+}
+
+// showNA shows the tile's graph labelled N/A
+func (p *Plugin) showNA(g *graph.Graph, data *actionData) {
+	g.SetLabelText(1, "N/A")
+	bts, err := g.EncodePNG()
+	if err != nil {
+		log.Printf("Failed to encode graph: %v\n", err)
+		return
+	}
+	if err := p.sd.SetImage(data.context, bts); err != nil {
+		log.Printf("Failed to setImage: %v\n", err)
+	}
+}
+
+func (p *Plugin) updateTile(ctx context.Context, batch readingBatch, st sourceStatus, data *actionData) {
 	if data.action != "com.exension.hwinfo.reading" {
-		log.Printf("Unknown action updateTiles: %s\n", data.action)
+		log.Printf("Unknown action updateTile: %s\n", data.action)
 		return
 	}
 
@@ -157,15 +456,19 @@ func (p *Plugin) updateTiles(data *actionData) {
 		return
 	}
 
-	if !p.appLaunched {
+	if !st.available() {
 		if !data.settings.InErrorState {
-			payload := evStatus{Error: true, Message: "HWiNFO Unavailable"}
+			payload := evStatus{Error: true, Message: st.message()}
 			err := p.sd.SendToPropertyInspector("com.exension.hwinfo.reading", data.context, payload)
 			if err != nil {
-				log.Println("updateTiles SendToPropertyInspector", err)
+				log.Println("updateTile SendToPropertyInspector", err)
 			}
-			data.settings.InErrorState = true
-			p.sd.SetSettings(data.context, &data.settings)
+			data = p.saveSettings(data, func(s *actionSettings) { s.InErrorState = true })
+		}
+		if !st.hwinfo() || st.unsupported() {
+			// launching HWiNFO does not help a remote or other backend
+			p.showNA(g, data)
+			return
 		}
 		bts, err := ioutil.ReadFile("./launch-hwinfo.png")
 		if err != nil {
@@ -185,18 +488,43 @@ func (p *Plugin) updateTiles(data *actionData) {
 		payload := evStatus{Error: false, Message: "show_ui"}
 		err := p.sd.SendToPropertyInspector("com.exension.hwinfo.reading", data.context, payload)
 		if err != nil {
-			log.Println("updateTiles SendToPropertyInspector", err)
+			log.Println("updateTile SendToPropertyInspector", err)
 		}
-		data.settings.InErrorState = false
-		p.sd.SetSettings(data.context, &data.settings)
+		data = p.saveSettings(data, func(s *actionSettings) { s.InErrorState = false })
 	}
 
-	s := data.settings
-	r, err := p.getReading(s.SensorUID, s.ReadingID)
+	r, err := p.reading(batch, data.settings.SensorUID, data.settings.ReadingID)
 	if err != nil {
-		log.Printf("getReading failed: %v\n", err)
-		return
+		log.Printf("reading failed: %v\n", err)
+		// only readings gone from their source are looked for elsewhere
+		if errors.Is(err, hwsensorsservice.ErrUnavailable) {
+			// e.g. a scrape target of an otherwise active source
+			p.showNA(g, data)
+			return
+		}
+		if !errors.Is(err, hwsensorsservice.ErrReadingNotFound) && !errors.Is(err, hwsensorsservice.ErrSensorNotFound) {
+			return
+		}
+		rebindKey := "rebind:" + data.context
+		if !p.retries.allow(rebindKey) {
+			return
+		}
+		r, data, err = p.rebindReading(ctx, data)
+		p.retries.done(rebindKey, err)
+		if err != nil {
+			log.Printf("rebindReading failed: %v\n", err)
+			return
+		}
 	}
+	// tiles configured before fingerprints existed
+	if fpKey := "fingerprint:" + data.context; data.settings.Fingerprint == nil && p.retries.allow(fpKey) {
+		fp, err := hwsensorsservice.NewResolver(p.hw).Fingerprint(ctx, data.settings.SensorUID, data.settings.ReadingID)
+		p.retries.done(fpKey, err)
+		if err == nil {
+			data = p.saveSettings(data, func(s *actionSettings) { s.Fingerprint = &fp })
+		}
+	}
+	s := data.settings
 
 	v := r.Value()
 	if s.Divisor != "" {
@@ -209,6 +537,9 @@ func (p *Plugin) updateTiles(data *actionData) {
 		v = r.Value() / fdiv
 	}
 	g.Update(v)
+	if s.LabelAsTitle && s.Title == "" {
+		g.SetLabelText(0, r.LabelUser())
+	}
 	var text string
 	if f := s.Format; f != "" {
 		text = fmt.Sprintf(f, v)
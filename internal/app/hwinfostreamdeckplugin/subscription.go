@@ -0,0 +1,110 @@
+package hwinfostreamdeckplugin
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+// readingCache the latest readings pushed by the subscription, tiles
+// render from it instead of asking every source on every tick
+type readingCache struct {
+	mu       sync.RWMutex
+	readings map[hwsensorsservice.ReadingKey]hwsensorsservice.Reading
+}
+
+func newReadingCache() *readingCache {
+	return &readingCache{readings: make(map[hwsensorsservice.ReadingKey]hwsensorsservice.Reading)}
+}
+
+func (rc *readingCache) get(k hwsensorsservice.ReadingKey) (hwsensorsservice.Reading, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	r, ok := rc.readings[k]
+	return r, ok
+}
+
+func (rc *readingCache) apply(u hwsensorsservice.Update) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for k, r := range u.Readings {
+		rc.readings[k] = r
+	}
+	// missing readings fall back to getReading's lookup and rebinding
+	for _, k := range u.Missing {
+		delete(rc.readings, k)
+	}
+}
+
+func (rc *readingCache) clear() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.readings = make(map[hwsensorsservice.ReadingKey]hwsensorsservice.Reading)
+}
+
+// subscribe keeps a subscription to the readings of all tiles, renewing
+// it when tiles change or the subscription ended
+func (p *Plugin) subscribe() {
+	var (
+		keys   []hwsensorsservice.ReadingKey
+		cancel context.CancelFunc = func() {}
+		done   chan struct{}
+	)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for ; ; <-ticker.C {
+		next := p.am.readingKeys()
+		ended := done == nil
+		if done != nil {
+			select {
+			case <-done:
+				ended = true
+			default:
+			}
+		}
+		if !ended && equalKeys(keys, next) {
+			continue
+		}
+
+		cancel()
+		p.cache.clear()
+		keys, done = next, nil
+		if len(keys) == 0 {
+			continue
+		}
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		ch, err := p.hw.Subscribe(ctx, keys, 0)
+		if err != nil {
+			log.Printf("Subscribe: %v\n", err)
+			continue
+		}
+		done = make(chan struct{})
+		go func(done chan struct{}) {
+			defer close(done)
+			for u := range ch {
+				if u.Err != nil {
+					log.Printf("Subscription ended: %v\n", u.Err)
+					p.cache.clear()
+					return
+				}
+				p.cache.apply(u)
+			}
+		}(done)
+	}
+}
+
+func equalKeys(a, b []hwsensorsservice.ReadingKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,5 +1,7 @@
 package hwinfostreamdeckplugin
 
+import hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+
 type actionSettings struct {
 	SensorUID       string  `json:"sensorUid"`
 	ReadingID       int32   `json:"readingId,string"`
@@ -17,6 +19,13 @@ type actionSettings struct {
 	HighlightColor  string  `json:"highlightColor"`
 	ValueTextColor  string  `json:"valueTextColor"`
 	InErrorState    bool    `json:"inErrorState"`
+
+	// LabelAsTitle shows the reading label as renamed in HWiNFO
+	// when no title is set
+	LabelAsTitle bool `json:"labelAsTitle"`
+
+	// Fingerprint re-binds the tile when SensorUID/ReadingID disappear
+	Fingerprint *hwsensorsservice.Fingerprint `json:"fingerprint,omitempty"`
 }
 
 type actionData struct {
@@ -31,8 +40,9 @@ type evStatus struct {
 }
 
 type evSendSensorsPayloadSensor struct {
-	UID  string `json:"uid"`
-	Name string `json:"name"`
+	UID      string `json:"uid"`
+	Name     string `json:"name"`
+	NameUser string `json:"nameUser"`
 }
 
 type evSendSensorsPayload struct {
@@ -41,9 +51,10 @@ type evSendSensorsPayload struct {
 }
 
 type evSendReadingsPayloadReading struct {
-	ID     int32  `json:"id,string"`
-	Label  string `json:"label"`
-	Prefix string `json:"prefix"`
+	ID        int32  `json:"id,string"`
+	Label     string `json:"label"`
+	LabelUser string `json:"labelUser"`
+	Prefix    string `json:"prefix"`
 }
 
 type evSendReadingsPayload struct {
@@ -57,4 +68,5 @@ type evSdpiCollection struct {
 	Key       string   `json:"key"`
 	Selection []string `json:"selection"`
 	Value     string   `json:"value"`
+	Checked   bool     `json:"checked"`
 }
@@ -0,0 +1,310 @@
+// Package backend serves hardware sensors from sources other than
+// HWiNFO as a hwsensorsservice.HardwareService. A Source samples current
+// values, Service polls it and tracks min, max and average the way
+// HWiNFO does.
+package backend
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+// Reading a sampled reading value
+type Reading struct {
+	// ID unique within the sensor and stable across polls
+	ID    int32
+	Type  hwsensorsservice.ReadingType
+	Label string
+	Unit  string
+	Value float64
+
+	// HasMinMax and HasAvg the source reports ValueMin, ValueMax
+	// or ValueAvg itself, otherwise they are tracked across polls
+	HasMinMax bool
+	HasAvg    bool
+	ValueMin  float64
+	ValueMax  float64
+	ValueAvg  float64
+}
+
+// Sensor a sampled sensor and its readings
+type Sensor struct {
+	// ID unique and stable across polls and restarts
+	ID       string
+	Name     string
+	Readings []Reading
+	// Err the sensor could not be sampled this poll, e.g. a scrape
+	// target is down. It is still listed, its readings are reported
+	// unavailable instead of gone.
+	Err error
+}
+
+// SensorID the ID of a sensor of backend scheme, the path joined by
+// slashes after the scheme and hwsensorsservice.SchemeSeparator
+func SensorID(scheme string, path ...string) string {
+	return scheme + hwsensorsservice.SchemeSeparator + strings.Join(path, "/")
+}
+
+// Source samples the current sensors, e.g. from sysfs
+type Source interface {
+	Read() ([]Sensor, error)
+}
+
+type readingKey struct {
+	sensorID  string
+	readingID int32
+}
+
+type stats struct {
+	min, max, sum float64
+	n             int
+}
+
+// Service implements hwsensorsservice.HardwareService for the last
+// successful poll of a Source
+type Service struct {
+	name string
+	src  Source
+
+	mu       sync.RWMutex
+	pollTime uint64
+	lastRead time.Time
+	sensors  []hwsensorsservice.Sensor
+	readings map[string][]hwsensorsservice.Reading
+	// sensorErrs errors of the sensors failing the last poll
+	sensorErrs map[string]error
+	stats      map[readingKey]*stats
+	err        error
+	// lastErr is kept after the next successful poll, for Status
+	lastErr error
+}
+
+// NewService creates a Service polling src, nothing is served
+// before the first Poll. name is the backend reported by Status.
+func NewService(name string, src Source) *Service {
+	return &Service{
+		name:  name,
+		src:   src,
+		stats: make(map[readingKey]*stats),
+	}
+}
+
+// Poll samples the source once, a failed poll is reported
+// by the service until the next successful poll
+func (s *Service) Poll() error {
+	sensors, err := s.src.Read()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.err, s.lastErr = err, err
+		return err
+	}
+
+	seen := make(map[readingKey]bool)
+	s.sensors = make([]hwsensorsservice.Sensor, 0, len(sensors))
+	s.readings = make(map[string][]hwsensorsservice.Reading, len(sensors))
+	s.sensorErrs = make(map[string]error)
+	for _, sens := range sensors {
+		s.sensors = append(s.sensors, sensor{sens.ID, sens.Name})
+		if sens.Err != nil {
+			s.sensorErrs[sens.ID] = sens.Err
+			s.lastErr = sens.Err
+			// keep tracking the readings until the sensor is back
+			for k := range s.stats {
+				if k.sensorID == sens.ID {
+					seen[k] = true
+				}
+			}
+			continue
+		}
+		readings := make([]hwsensorsservice.Reading, 0, len(sens.Readings))
+		for _, r := range sens.Readings {
+			k := readingKey{sens.ID, r.ID}
+			seen[k] = true
+			min, max, avg := s.track(k, r.Value)
+			if !r.HasMinMax {
+				r.ValueMin, r.ValueMax = min, max
+			}
+			if !r.HasAvg {
+				r.ValueAvg = avg
+			}
+			readings = append(readings, reading{r})
+		}
+		s.readings[sens.ID] = readings
+	}
+	// forget readings that disappeared
+	for k := range s.stats {
+		if !seen[k] {
+			delete(s.stats, k)
+		}
+	}
+	s.lastRead = time.Now()
+	s.pollTime = uint64(s.lastRead.Unix())
+	s.err = nil
+	return nil
+}
+
+func (s *Service) track(k readingKey, v float64) (min, max, avg float64) {
+	st, ok := s.stats[k]
+	if !ok {
+		st = &stats{min: v, max: v}
+		s.stats[k] = st
+	}
+	if v < st.min {
+		st.min = v
+	}
+	if v > st.max {
+		st.max = v
+	}
+	st.sum += v
+	st.n++
+	return st.min, st.max, st.sum / float64(st.n)
+}
+
+// Run polls every interval until ctx is done, errors are logged
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Poll(); err != nil {
+			log.Printf("backend poll failed: %v\n", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Service) current(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if s.err != nil {
+		return fmt.Errorf("%w: %v", hwsensorsservice.ErrUnavailable, s.err)
+	}
+	if s.pollTime == 0 {
+		return fmt.Errorf("%w: no poll yet", hwsensorsservice.ErrUnavailable)
+	}
+	return nil
+}
+
+// PollTime implements hwsensorsservice.HardwareService,
+// unix time of the last successful poll
+func (s *Service) PollTime(ctx context.Context) (uint64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.current(ctx); err != nil {
+		return 0, err
+	}
+	return s.pollTime, nil
+}
+
+// Sensors implements hwsensorsservice.HardwareService
+func (s *Service) Sensors(ctx context.Context) ([]hwsensorsservice.Sensor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.current(ctx); err != nil {
+		return nil, err
+	}
+	return s.sensors, nil
+}
+
+// ReadingsForSensorID implements hwsensorsservice.HardwareService
+func (s *Service) ReadingsForSensorID(ctx context.Context, id string) ([]hwsensorsservice.Reading, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.current(ctx); err != nil {
+		return nil, err
+	}
+	return s.readingsFor(id)
+}
+
+// readingsFor the readings of sensor id, the caller holds mu
+func (s *Service) readingsFor(id string) ([]hwsensorsservice.Reading, error) {
+	if err, ok := s.sensorErrs[id]; ok {
+		return nil, fmt.Errorf("%w: sensor %s: %v", hwsensorsservice.ErrUnavailable, id, err)
+	}
+	readings, ok := s.readings[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: readings for sensor id %s do not exist", hwsensorsservice.ErrSensorNotFound, id)
+	}
+	return readings, nil
+}
+
+// ReadingsByKeys implements hwsensorsservice.HardwareService
+func (s *Service) ReadingsByKeys(ctx context.Context, keys []hwsensorsservice.ReadingKey) ([]hwsensorsservice.ReadingResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.current(ctx); err != nil {
+		return nil, err
+	}
+	return hwsensorsservice.LookupReadings(keys, s.readingsFor), nil
+}
+
+// Status implements hwsensorsservice.HardwareService, a failing
+// source is reported as not found. Sensors failing while others are
+// read keep the source active, their error is the LastError.
+func (s *Service) Status(ctx context.Context) (hwsensorsservice.Status, error) {
+	if err := ctx.Err(); err != nil {
+		return hwsensorsservice.Status{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := hwsensorsservice.Status{
+		Backend:  s.name,
+		PollTime: s.pollTime,
+		LastRead: s.lastRead,
+		Sensors:  len(s.sensors),
+	}
+	if s.lastErr != nil {
+		st.LastError = s.lastErr.Error()
+	}
+	switch {
+	case s.err != nil:
+		st.State = hwsensorsservice.StateNotFound
+	case s.pollTime != 0:
+		st.State = hwsensorsservice.StateActive
+	}
+	for _, readings := range s.readings {
+		st.Readings += len(readings)
+	}
+	return st, nil
+}
+
+type sensor struct {
+	id, name string
+}
+
+func (s sensor) ID() string       { return s.id }
+func (s sensor) Name() string     { return s.name }
+func (s sensor) NameUser() string { return s.name }
+
+type reading struct {
+	r Reading
+}
+
+func (r reading) ID() int32         { return r.r.ID }
+func (r reading) TypeI() int32      { return int32(r.r.Type) }
+func (r reading) Type() string      { return r.r.Type.String() }
+func (r reading) Label() string     { return r.r.Label }
+func (r reading) LabelUser() string { return r.r.Label }
+func (r reading) Unit() string      { return r.r.Unit }
+func (r reading) Value() float64    { return r.r.Value }
+func (r reading) ValueMin() float64 { return r.r.ValueMin }
+func (r reading) ValueMax() float64 { return r.r.ValueMax }
+func (r reading) ValueAvg() float64 { return r.r.ValueAvg }
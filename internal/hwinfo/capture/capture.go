@@ -0,0 +1,181 @@
+// Package capture records HWiNFO shared memory snapshots to a file
+// and replays them as a stream of hwinfo.Result
+//
+// File format, little endian:
+//
+//	header: magic "HWiNFOCP" (8 bytes), format version (uint32)
+//	frame:  unix time in nanoseconds (int64), length (uint32), raw HWiNFO_SENSORS_SHARED_MEM2 (length bytes)
+package capture
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
+)
+
+const (
+	magic         = "HWiNFOCP"
+	formatVersion = 1
+
+	// guards against allocating garbage lengths from corrupt files
+	maxFrameLen = 64 << 20
+)
+
+// ErrBadFormat the file is not a capture or uses an unknown format version
+var ErrBadFormat = errors.New("capture: bad format")
+
+// Frame a single timestamped shared memory snapshot
+type Frame struct {
+	Time time.Time
+	Data []byte
+}
+
+// Writer writes frames to a capture file
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter writes the capture header and returns a Writer
+func NewWriter(w io.Writer) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(magic); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(bw, binary.LittleEndian, uint32(formatVersion)); err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	return &Writer{w: bw}, nil
+}
+
+// WriteFrame appends a snapshot taken at t
+func (w *Writer) WriteFrame(t time.Time, shmem *hwinfo.SharedMemory) error {
+	data := shmem.Bytes()
+	var hdr [12]byte
+	binary.LittleEndian.PutUint64(hdr[0:], uint64(t.UnixNano()))
+	binary.LittleEndian.PutUint32(hdr[8:], uint32(len(data)))
+	if _, err := w.w.Write(hdr[:]); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(data); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// Reader reads frames from a capture file
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader validates the capture header and returns a Reader
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	var hdr [len(magic) + 4]byte
+	if _, err := io.ReadFull(br, hdr[:]); err != nil {
+		return nil, fmt.Errorf("%w: reading header: %v", ErrBadFormat, err)
+	}
+	if string(hdr[:len(magic)]) != magic {
+		return nil, fmt.Errorf("%w: invalid magic %q", ErrBadFormat, hdr[:len(magic)])
+	}
+	if v := binary.LittleEndian.Uint32(hdr[len(magic):]); v != formatVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrBadFormat, v)
+	}
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next frame, io.EOF when there are no more frames
+func (r *Reader) Next() (Frame, error) {
+	var hdr [12]byte
+	if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return Frame{}, fmt.Errorf("%w: truncated frame header", ErrBadFormat)
+		}
+		return Frame{}, err
+	}
+	n := binary.LittleEndian.Uint32(hdr[8:])
+	if n > maxFrameLen {
+		return Frame{}, fmt.Errorf("%w: frame length %d too large", ErrBadFormat, n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return Frame{}, fmt.Errorf("%w: truncated frame: %v", ErrBadFormat, err)
+	}
+	return Frame{
+		Time: time.Unix(0, int64(binary.LittleEndian.Uint64(hdr[0:]))),
+		Data: data,
+	}, nil
+}
+
+// Record writes every successful result from ch to w and
+// passes all results through to the returned channel
+func Record(ch <-chan hwinfo.Result, w *Writer) <-chan hwinfo.Result {
+	out := make(chan hwinfo.Result)
+	go func() {
+		defer close(out)
+		for r := range ch {
+			if r.Err == nil && r.Shmem != nil {
+				if err := w.WriteFrame(time.Now(), r.Shmem); err != nil {
+					r = hwinfo.Result{Err: fmt.Errorf("capture record: %w", err)}
+				}
+			}
+			out <- r
+		}
+	}()
+	return out
+}
+
+// Replay delivers the frames of a capture over a channel in the same
+// manner as hwinfo.StreamSharedMem. Frames are spaced by their original
+// timing divided by speed, a speed <= 0 replays as fast as possible.
+// The channel is closed after the last frame, the first read error
+// or once ctx is done.
+func Replay(ctx context.Context, r *Reader, speed float64) <-chan hwinfo.Result {
+	ch := make(chan hwinfo.Result)
+	go func() {
+		defer close(ch)
+		send := func(r hwinfo.Result) bool {
+			select {
+			case ch <- r:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var last time.Time
+		for {
+			f, err := r.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				send(hwinfo.Result{Err: err})
+				return
+			}
+			if !last.IsZero() && speed > 0 {
+				timer := time.NewTimer(time.Duration(float64(f.Time.Sub(last)) / speed))
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+			last = f.Time
+			shmem, err := hwinfo.NewSharedMemory(f.Data)
+			if !send(hwinfo.Result{Shmem: shmem, Err: err}) {
+				return
+			}
+		}
+	}()
+	return ch
+}
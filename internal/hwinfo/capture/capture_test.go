@@ -0,0 +1,187 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
+)
+
+// snapshot a shared memory with one reading of the given value
+func snapshot(t *testing.T, pollTime uint64, value float64) *hwinfo.SharedMemory {
+	t.Helper()
+	data := hwinfo.Encode(nil, pollTime,
+		[]hwinfo.SensorElement{{SensorID: 1, NameOrig: "CPU [#0]", NameUser: "CPU [#0]"}},
+		[]hwinfo.ReadingElement{{Type: hwinfo.ReadingTypeTemp, ReadingID: 10, LabelOrig: "Core", LabelUser: "Core", Unit: "°C", Value: value}},
+	)
+	shmem, err := hwinfo.NewSharedMemory(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return shmem
+}
+
+// writeCapture a capture of one frame per gap, the first at start
+func writeCapture(t *testing.T, start time.Time, gaps ...time.Duration) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := start
+	for i, gap := range gaps {
+		at = at.Add(gap)
+		if err := w.WriteFrame(at, snapshot(t, uint64(i+1), float64(40+i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	start := time.Unix(1600000000, 123456789)
+	gaps := []time.Duration{0, time.Second, 1500 * time.Millisecond}
+	r, err := NewReader(bytes.NewReader(writeCapture(t, start, gaps...)))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+
+	at := start
+	for i, gap := range gaps {
+		at = at.Add(gap)
+		f, err := r.Next()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if !f.Time.Equal(at) {
+			t.Errorf("frame %d time = %v, want %v", i, f.Time, at)
+		}
+		if want := snapshot(t, uint64(i+1), float64(40+i)).Bytes(); !bytes.Equal(f.Data, want) {
+			t.Errorf("frame %d data differs from the snapshot written", i)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("after last frame err = %v, want io.EOF", err)
+	}
+}
+
+func TestBadHeader(t *testing.T) {
+	valid := writeCapture(t, time.Now())
+	badVersion := append([]byte(magic), 2, 0, 0, 0)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short", valid[:len(magic)+2]},
+		{"magic", append([]byte("HWiNFOXX"), valid[len(magic):]...)},
+		{"version", badVersion},
+	}
+	for _, tt := range tests {
+		if _, err := NewReader(bytes.NewReader(tt.data)); !errors.Is(err, ErrBadFormat) {
+			t.Errorf("%s: err = %v, want ErrBadFormat", tt.name, err)
+		}
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	data := writeCapture(t, time.Now(), 0, time.Second)
+	frameLen := (len(data) - len(magic) - 4) / 2
+	second := len(data) - frameLen
+
+	tests := []struct {
+		name string
+		end  int
+		want error
+	}{
+		{"frame header", second + 5, ErrBadFormat},
+		{"frame data", len(data) - 1, ErrBadFormat},
+		{"frame boundary", second, io.EOF},
+	}
+	for _, tt := range tests {
+		r, err := NewReader(bytes.NewReader(data[:tt.end]))
+		if err != nil {
+			t.Fatalf("%s: NewReader: %v", tt.name, err)
+		}
+		if _, err := r.Next(); err != nil {
+			t.Fatalf("%s: first frame: %v", tt.name, err)
+		}
+		if _, err := r.Next(); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// Replay reports the broken frame and stops
+	r, err := NewReader(bytes.NewReader(data[:len(data)-1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []hwinfo.Result
+	for res := range Replay(context.Background(), r, 0) {
+		results = append(results, res)
+	}
+	if len(results) != 2 || results[0].Err != nil || !errors.Is(results[1].Err, ErrBadFormat) {
+		t.Errorf("replay results = %+v, want a frame then ErrBadFormat", results)
+	}
+}
+
+func TestReplayTiming(t *testing.T) {
+	data := writeCapture(t, time.Now(), 0, 100*time.Millisecond, 100*time.Millisecond)
+	tests := []struct {
+		speed    float64
+		min, max time.Duration
+	}{
+		{1, 200 * time.Millisecond, 2 * time.Second},
+		{4, 50 * time.Millisecond, 190 * time.Millisecond},
+		{0, 0, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		r, err := NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		var polls []uint64
+		for res := range Replay(context.Background(), r, tt.speed) {
+			if res.Err != nil {
+				t.Fatalf("speed %v: %v", tt.speed, res.Err)
+			}
+			polls = append(polls, res.Shmem.PollTime())
+		}
+		d := time.Since(start)
+		if len(polls) != 3 || polls[0] != 1 || polls[2] != 3 {
+			t.Errorf("speed %v: poll times %v, want frames in order", tt.speed, polls)
+		}
+		if d < tt.min || d > tt.max {
+			t.Errorf("speed %v: replay took %v, want %v to %v", tt.speed, d, tt.min, tt.max)
+		}
+	}
+}
+
+func TestReplayCancel(t *testing.T) {
+	r, err := NewReader(bytes.NewReader(writeCapture(t, time.Now(), 0, time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := Replay(ctx, r, 1)
+	if res := <-ch; res.Err != nil {
+		t.Fatalf("first frame: %v", res.Err)
+	}
+
+	// waiting an hour for the second frame
+	cancel()
+	select {
+	case res, ok := <-ch:
+		if ok {
+			t.Errorf("got %+v after cancel, want the channel closed", res)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("replay did not stop on cancel")
+	}
+}
@@ -0,0 +1,400 @@
+// Package csvlog parses the CSV sensor logs HWiNFO writes when logging
+// is enabled, either tailing a log that is still being written or
+// importing a finished log for replay
+//
+// The first row holds a column per reading, labeled "Label [unit]",
+// after the Date and Time columns. Once logging stops HWiNFO repeats
+// that row followed by a row with the sensor of each column.
+package csvlog
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/util"
+)
+
+// ErrNoHeader the log does not start with a header row
+var ErrNoHeader = errors.New("csvlog: missing header row")
+
+// DefaultSensor name of the sensor of columns until the log
+// names their sensors
+const DefaultSensor = "HWiNFO Log"
+
+// Column a logged reading
+type Column struct {
+	// Index of the CSV column
+	Index int
+	// Sensor the reading belongs to, DefaultSensor when not known
+	Sensor string
+	Label  string
+	Unit   string
+	Type   hwinfo.ReadingType
+}
+
+// Header columns of a log
+type Header struct {
+	Columns []Column
+	dateCol int
+	timeCol int
+}
+
+// Row a logged row, Values are in Columns order
+// and NaN when the cell holds no number
+type Row struct {
+	// Time zero when not parsable
+	Time   time.Time
+	Values []float64
+}
+
+// ParseHeader parses the first row of a log
+func ParseHeader(record []string) (*Header, error) {
+	h := &Header{dateCol: -1, timeCol: -1}
+	for i, cell := range record {
+		cell = strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff"))
+		switch {
+		case cell == "":
+			// rows end with a separator
+		case strings.EqualFold(cell, "Date"):
+			h.dateCol = i
+		case strings.EqualFold(cell, "Time"):
+			h.timeCol = i
+		default:
+			label, unit := splitUnit(cell)
+			h.Columns = append(h.Columns, Column{
+				Index:  i,
+				Sensor: DefaultSensor,
+				Label:  label,
+				Unit:   unit,
+				Type:   typeForUnit(unit),
+			})
+		}
+	}
+	if h.dateCol < 0 || len(h.Columns) == 0 {
+		return nil, ErrNoHeader
+	}
+	return h, nil
+}
+
+// splitUnit "CPU Package [°C]" into label and unit
+func splitUnit(cell string) (string, string) {
+	if strings.HasSuffix(cell, "]") {
+		if i := strings.LastIndex(cell, " ["); i >= 0 {
+			return cell[:i], cell[i+2 : len(cell)-1]
+		}
+	}
+	return cell, ""
+}
+
+func typeForUnit(unit string) hwinfo.ReadingType {
+	switch unit {
+	case "°C", "°F":
+		return hwinfo.ReadingTypeTemp
+	case "V":
+		return hwinfo.ReadingTypeVolt
+	case "RPM":
+		return hwinfo.ReadingTypeFan
+	case "A":
+		return hwinfo.ReadingTypeCurrent
+	case "W":
+		return hwinfo.ReadingTypePower
+	case "MHz":
+		return hwinfo.ReadingTypeClock
+	case "%", "MB", "GB", "KB/s", "MB/s", "T":
+		return hwinfo.ReadingTypeUsage
+	case "":
+		return hwinfo.ReadingTypeNone
+	default:
+		return hwinfo.ReadingTypeOther
+	}
+}
+
+// isHeader record repeats the header row
+func (h *Header) isHeader(record []string) bool {
+	return h.dateCol < len(record) && strings.EqualFold(strings.TrimSpace(record[h.dateCol]), "Date")
+}
+
+// isSensors record names the sensor of every column, no cell is a number
+func (h *Header) isSensors(record []string) bool {
+	named := false
+	for _, c := range h.Columns {
+		if c.Index >= len(record) {
+			continue
+		}
+		cell := strings.TrimSpace(record[c.Index])
+		if _, ok := parseValue(cell); ok {
+			return false
+		}
+		named = named || cell != ""
+	}
+	return named
+}
+
+// setSensors applies a sensor row, an empty cell continues the
+// sensor of the column before
+func (h *Header) setSensors(record []string) {
+	sensor := DefaultSensor
+	for i := range h.Columns {
+		c := &h.Columns[i]
+		if c.Index < len(record) {
+			if s := strings.TrimSpace(record[c.Index]); s != "" {
+				sensor = s
+			}
+		}
+		c.Sensor = sensor
+	}
+}
+
+// time layouts depend on the locale of the logging machine
+var timeLayouts = []string{
+	"2.1.2006 15:04:05.000",
+	"2.1.2006 15:04:05",
+	"1/2/2006 15:04:05.000",
+	"1/2/2006 15:04:05",
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05",
+}
+
+// ParseRow parses a data row
+func (h *Header) ParseRow(record []string) Row {
+	row := Row{Values: make([]float64, len(h.Columns))}
+	if h.dateCol < len(record) && h.timeCol >= 0 && h.timeCol < len(record) {
+		ts := strings.TrimSpace(record[h.dateCol]) + " " + strings.TrimSpace(record[h.timeCol])
+		for _, layout := range timeLayouts {
+			if t, err := time.ParseInLocation(layout, ts, time.Local); err == nil {
+				row.Time = t
+				break
+			}
+		}
+	}
+	for i, c := range h.Columns {
+		row.Values[i] = math.NaN()
+		if c.Index < len(record) {
+			if v, ok := parseValue(record[c.Index]); ok {
+				row.Values[i] = v
+			}
+		}
+	}
+	return row
+}
+
+func parseValue(cell string) (float64, bool) {
+	switch cell = strings.TrimSpace(cell); cell {
+	case "Yes":
+		return 1, true
+	case "No":
+		return 0, true
+	}
+	v, err := strconv.ParseFloat(cell, 64)
+	return v, err == nil
+}
+
+// sensorColumns columns of a sensor, indexes into Header.Columns
+type sensorColumns struct {
+	name    string
+	columns []int
+}
+
+// sensors groups columns by sensor in order of appearance
+func (h *Header) sensors() []sensorColumns {
+	var sensors []sensorColumns
+	idx := make(map[string]int)
+	for i, c := range h.Columns {
+		j, ok := idx[c.Sensor]
+		if !ok {
+			j = len(sensors)
+			idx[c.Sensor] = j
+			sensors = append(sensors, sensorColumns{name: c.Sensor})
+		}
+		sensors[j].columns = append(sensors[j].columns, i)
+	}
+	return sensors
+}
+
+// stats min, max and average of every column over the rows added
+type stats struct {
+	min, max, sum []float64
+	n             []int
+}
+
+func newStats(columns int) *stats {
+	return &stats{
+		min: make([]float64, columns),
+		max: make([]float64, columns),
+		sum: make([]float64, columns),
+		n:   make([]int, columns),
+	}
+}
+
+func (s *stats) add(row Row) {
+	for i, v := range row.Values {
+		if math.IsNaN(v) {
+			continue
+		}
+		if s.n[i] == 0 || v < s.min[i] {
+			s.min[i] = v
+		}
+		if s.n[i] == 0 || v > s.max[i] {
+			s.max[i] = v
+		}
+		s.sum[i] += v
+		s.n[i]++
+	}
+}
+
+func (s *stats) get(i int) (min, max, avg float64) {
+	if s.n[i] == 0 {
+		return 0, 0, 0
+	}
+	return s.min[i], s.max[i], s.sum[i] / float64(s.n[i])
+}
+
+// newCSVReader reads the variable length records HWiNFO writes
+func newCSVReader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	return cr
+}
+
+// decodeRecord HWiNFO writes logs in the ANSI code page,
+// cells that are not UTF-8 are decoded as ISO8859-1
+func decodeRecord(record []string) []string {
+	for i, cell := range record {
+		if !utf8.ValidString(cell) {
+			record[i] = util.DecodeCString([]byte(cell))
+		}
+	}
+	return record
+}
+
+// Log a finished log
+type Log struct {
+	Header *Header
+	Rows   []Row
+}
+
+// ReadLog imports a whole log, sensors are named when the log
+// ends with the sensor row
+func ReadLog(r io.Reader) (*Log, error) {
+	cr := newCSVReader(r)
+	record, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csvlog: reading header: %w", err)
+	}
+	h, err := ParseHeader(decodeRecord(record))
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{Header: h}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return l, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csvlog: %w", err)
+		}
+		record = decodeRecord(record)
+		switch {
+		case h.isHeader(record):
+		case h.isSensors(record):
+			h.setSensors(record)
+		default:
+			l.Rows = append(l.Rows, h.ParseRow(record))
+		}
+	}
+}
+
+// sensorID of the shared memory sensors of a log, unused by HWiNFO,
+// sensors are told apart by instance
+const sensorID = 0xc5f00000
+
+// encode builds a shared memory snapshot of row
+func (l *Log) encode(dst []byte, pollTime uint64, row Row, st *stats) []byte {
+	var sensors []hwinfo.SensorElement
+	var readings []hwinfo.ReadingElement
+	for i, sens := range l.Header.sensors() {
+		sensors = append(sensors, hwinfo.SensorElement{
+			SensorID:   sensorID,
+			SensorInst: uint32(i),
+			NameOrig:   sens.name,
+			NameUser:   sens.name,
+		})
+		for j, ci := range sens.columns {
+			if math.IsNaN(row.Values[ci]) {
+				continue
+			}
+			c := l.Header.Columns[ci]
+			min, max, avg := st.get(ci)
+			readings = append(readings, hwinfo.ReadingElement{
+				Type:        c.Type,
+				SensorIndex: uint32(i),
+				ReadingID:   uint32(j),
+				LabelOrig:   c.Label,
+				LabelUser:   c.Label,
+				Unit:        c.Unit,
+				Value:       row.Values[ci],
+				ValueMin:    min,
+				ValueMax:    max,
+				ValueAvg:    avg,
+			})
+		}
+	}
+	return hwinfo.Encode(dst, pollTime, sensors, readings)
+}
+
+// Replay delivers the rows of l as shared memory snapshots in the same
+// manner as capture.Replay. Rows are spaced by their logged time divided
+// by speed, a speed <= 0 replays as fast as possible. The channel is
+// closed after the last row or once ctx is done.
+func (l *Log) Replay(ctx context.Context, speed float64) <-chan hwinfo.Result {
+	ch := make(chan hwinfo.Result)
+	go func() {
+		defer close(ch)
+		st := newStats(len(l.Header.Columns))
+		var last time.Time
+		var pollTime uint64
+		for i, row := range l.Rows {
+			if i > 0 && speed > 0 {
+				d := hwinfo.DefaultInterval
+				if !row.Time.IsZero() && !last.IsZero() {
+					d = row.Time.Sub(last)
+				}
+				timer := time.NewTimer(time.Duration(float64(d) / speed))
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+			last = row.Time
+
+			st.add(row)
+			// poll time must advance for consumers skipping unchanged
+			// snapshots, logs may be written more than once a second
+			if t := uint64(row.Time.Unix()); !row.Time.IsZero() && t > pollTime {
+				pollTime = t
+			} else {
+				pollTime++
+			}
+			shmem, err := hwinfo.NewSharedMemory(l.encode(nil, pollTime, row, st))
+			select {
+			case ch <- hwinfo.Result{Shmem: shmem, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
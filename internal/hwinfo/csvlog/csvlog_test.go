@@ -0,0 +1,192 @@
+package csvlog
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
+)
+
+func TestReadLog(t *testing.T) {
+	f, err := os.Open("testdata/hwinfo.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	l, err := ReadLog(f)
+	if err != nil {
+		t.Fatalf("ReadLog: %v", err)
+	}
+	if len(l.Rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(l.Rows))
+	}
+	cols := l.Header.Columns
+	if len(cols) != 7 {
+		t.Fatalf("got %d columns, want 7", len(cols))
+	}
+	c := cols[2]
+	if c.Label != "CPU (Tctl/Tdie)" || c.Unit != "°C" || c.Type != hwinfo.ReadingTypeTemp || c.Sensor != "CPU [#0]: AMD Ryzen 9 5900X: Enhanced" {
+		t.Errorf("column = %+v", c)
+	}
+	// empty sensor cells continue the sensor before
+	if cols[3].Sensor != c.Sensor {
+		t.Errorf("power sensor = %q", cols[3].Sensor)
+	}
+	if l.Rows[0].Time.Second() != 5 || l.Rows[0].Values[4] != 0 {
+		t.Errorf("row 0 = %v %v", l.Rows[0].Time, l.Rows[0].Values)
+	}
+	if !math.IsNaN(l.Rows[1].Values[6]) {
+		t.Errorf("empty cell = %v, want NaN", l.Rows[1].Values[6])
+	}
+
+	var last *hwinfo.SharedMemory
+	n := 0
+	for r := range l.Replay(context.Background(), 0) {
+		if r.Err != nil {
+			t.Fatalf("Replay: %v", r.Err)
+		}
+		last = r.Shmem
+		n++
+	}
+	if n != 3 {
+		t.Fatalf("replayed %d rows, want 3", n)
+	}
+	var sensors []string
+	for s := range last.IterSensors() {
+		sensors = append(sensors, s.NameOrig())
+	}
+	if len(sensors) != 4 || sensors[2] != "ASUS ROG STRIX X570-E" {
+		t.Errorf("sensors = %q", sensors)
+	}
+	for r := range last.IterReadings() {
+		if r.LabelOrig() == "CPU (Tctl/Tdie)" && (r.Value() != 50 || r.ValueMin() != 45.5 || r.ValueMax() != 55.5 || r.ValueAvg() != 50.333333333333336) {
+			t.Errorf("CPU temp = %v %v %v %v", r.Value(), r.ValueMin(), r.ValueMax(), r.ValueAvg())
+		}
+	}
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.csv")
+	write := func(s string, flag int) {
+		f, err := os.OpenFile(path, flag|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	tail := NewTail(path)
+
+	write("Date,Time,\"CPU [°C]\",\"Fan [RPM]\",\n18.10.2026,14:03:05.123,40,9", os.O_CREATE|os.O_TRUNC)
+	if _, err := tail.Read(); err == nil {
+		t.Error("expected error before the first complete row")
+	}
+
+	write("00,\n18.10.2026,14:03:07.123,50,1000,\n", os.O_APPEND)
+	sensors, err := tail.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(sensors) != 1 || sensors[0].Name != DefaultSensor || len(sensors[0].Readings) != 2 {
+		t.Fatalf("sensors = %+v", sensors)
+	}
+	r := sensors[0].Readings[0]
+	if r.Label != "CPU" || r.Unit != "°C" || r.Value != 50 || r.ValueMin != 40 || r.ValueMax != 50 || r.ValueAvg != 45 {
+		t.Errorf("reading = %+v", r)
+	}
+	if v := sensors[0].Readings[1].Value; v != 1000 {
+		t.Errorf("fan = %v, want 1000", v)
+	}
+
+	// logging restarted into the same file
+	write("Date,Time,\"GPU [°C]\",\n18.10.2026,15:00:00.000,60,\n", os.O_TRUNC)
+	sensors, err = tail.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if r := sensors[0].Readings[0]; r.Label != "GPU" || r.ValueMin != 60 {
+		t.Errorf("reading after restart = %+v", r)
+	}
+
+	// a new log moved in place of the old one, longer than it
+	replaced := filepath.Join(filepath.Dir(path), "new.csv")
+	log := "Date,Time,\"Fan [RPM]\",\n"
+	for i := 0; i < 5; i++ {
+		log += fmt.Sprintf("18.10.2026,16:00:0%d.000,%d,\n", i, 1200+i)
+	}
+	if err := os.WriteFile(replaced, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(replaced, path); err != nil {
+		t.Fatal(err)
+	}
+	sensors, err = tail.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if r := sensors[0].Readings[0]; r.Label != "Fan" || r.Value != 1204 || r.ValueMin != 1200 {
+		t.Errorf("reading after replace = %+v", r)
+	}
+}
+
+func TestTailHeaderIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.csv")
+	write := func(s string, flag int) {
+		f, err := os.OpenFile(path, flag|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	read := func(tail *Tail) map[string]int32 {
+		t.Helper()
+		sensors, err := tail.Read()
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if len(sensors) != 1 || sensors[0].ID != "csv:log" || sensors[0].Name != DefaultSensor {
+			t.Fatalf("sensors = %+v", sensors)
+		}
+		ids := make(map[string]int32)
+		for _, r := range sensors[0].Readings {
+			ids[fmt.Sprint(r.Label, r.Value)] = r.ID
+		}
+		return ids
+	}
+	header := "Date,Time,\"CPU [°C]\",\"GPU Temperature [°C]\",\"GPU Temperature [°C]\",\n"
+
+	tail := NewTail(path)
+	write(header, os.O_CREATE|os.O_TRUNC)
+	if _, err := tail.Read(); err == nil {
+		t.Error("expected error for a log of just the header")
+	}
+
+	write("18.10.2026,14:03:05.123,40,50,60,\n", os.O_APPEND)
+	ids := read(tail)
+	if len(ids) != 3 || ids["GPU Temperature50"] == ids["GPU Temperature60"] {
+		t.Fatalf("reading IDs = %v, want one per column", ids)
+	}
+
+	// the sensor names logged once logging stopped leave IDs as they are
+	write(header+",,\"CPU [#0]\",\"GPU [#0]\",\"GPU [#1]\",\n", os.O_APPEND)
+	if got := read(tail); fmt.Sprint(got) != fmt.Sprint(ids) {
+		t.Errorf("reading IDs after sensor names = %v, want %v", got, ids)
+	}
+
+	// columns keep their IDs in a new log of a different order
+	restarted := NewTail(path)
+	write("Date,Time,\"GPU Temperature [°C]\",\"CPU [°C]\",\"GPU Temperature [°C]\",\n18.10.2026,15:00:00.000,50,40,60,\n", os.O_TRUNC)
+	if got := read(restarted); fmt.Sprint(got) != fmt.Sprint(ids) {
+		t.Errorf("reading IDs of a new log = %v, want %v", got, ids)
+	}
+}
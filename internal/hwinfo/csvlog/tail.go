@@ -0,0 +1,192 @@
+package csvlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/backend"
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+// Tail follows a log HWiNFO is writing and serves its latest row,
+// with min, max and average over every row read since the start
+type Tail struct {
+	path string
+
+	mu      sync.Mutex
+	file    os.FileInfo
+	offset  int64
+	partial []byte
+	header  *Header
+	last    *Row
+	stats   *stats
+}
+
+// NewTail creates a Tail of the log at path
+func NewTail(path string) *Tail {
+	return &Tail{path: path}
+}
+
+// NewService creates a service for the log at path
+func NewService(path string) *backend.Service {
+	return backend.NewService("csv", NewTail(path))
+}
+
+func (t *Tail) reset() {
+	t.file = nil
+	t.offset = 0
+	t.partial = nil
+	t.header = nil
+	t.last = nil
+	t.stats = nil
+}
+
+// Read implements backend.Source, reading the rows appended
+// since the last Read
+func (t *Tail) Read() ([]backend.Sensor, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	f, err := os.Open(t.path)
+	if err != nil {
+		return nil, fmt.Errorf("csvlog: %w", err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("csvlog: %w", err)
+	}
+	// HWiNFO started a new log in place of the old one, either
+	// truncating it or replacing the file
+	if fi.Size() < t.offset || (t.file != nil && !os.SameFile(t.file, fi)) {
+		t.reset()
+	}
+	t.file = fi
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("csvlog: %w", err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("csvlog: %w", err)
+	}
+	t.offset += int64(len(data))
+
+	// only complete lines, the rest is read with the next poll
+	data = append(t.partial, data...)
+	end := bytes.LastIndexByte(data, '\n') + 1
+	t.partial = append([]byte(nil), data[end:]...)
+	if err := t.parse(data[:end]); err != nil {
+		return nil, err
+	}
+
+	if t.header == nil {
+		return nil, ErrNoHeader
+	}
+	if t.last == nil {
+		return nil, errors.New("csvlog: no rows logged yet")
+	}
+	return t.sensors(), nil
+}
+
+func (t *Tail) parse(lines []byte) error {
+	cr := newCSVReader(bytes.NewReader(lines))
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("csvlog: %w", err)
+		}
+		record = decodeRecord(record)
+
+		if t.header == nil || t.header.isHeader(record) {
+			h, err := ParseHeader(record)
+			if err != nil {
+				return err
+			}
+			// the header repeated once logging stopped
+			if t.header != nil && sameColumns(t.header, h) {
+				continue
+			}
+			t.header = h
+			t.last = nil
+			t.stats = newStats(len(h.Columns))
+			continue
+		}
+		// the sensor names HWiNFO logs once logging stopped
+		if t.header.isSensors(record) {
+			continue
+		}
+		row := t.header.ParseRow(record)
+		t.stats.add(row)
+		t.last = &row
+	}
+}
+
+func sameColumns(a, b *Header) bool {
+	if len(a.Columns) != len(b.Columns) {
+		return false
+	}
+	for i := range a.Columns {
+		if a.Columns[i].Label != b.Columns[i].Label || a.Columns[i].Unit != b.Columns[i].Unit {
+			return false
+		}
+	}
+	return true
+}
+
+// sensors the latest row as one sensor. HWiNFO only logs the sensor
+// of every column once logging stopped, until then a log can not be
+// told apart by sensor, so IDs only depend on the header.
+func (t *Tail) sensors() []backend.Sensor {
+	s := backend.Sensor{ID: backend.SensorID("csv", "log"), Name: DefaultSensor}
+	seen := make(map[[2]string]int)
+	for ci, c := range t.header.Columns {
+		id := columnID(c, seen)
+		v := t.last.Values[ci]
+		if math.IsNaN(v) {
+			continue
+		}
+		min, max, avg := t.stats.get(ci)
+		s.Readings = append(s.Readings, backend.Reading{
+			ID:        id,
+			Type:      hwsensorsservice.ReadingType(c.Type),
+			Label:     c.Label,
+			Unit:      c.Unit,
+			Value:     v,
+			HasMinMax: true,
+			HasAvg:    true,
+			ValueMin:  min,
+			ValueMax:  max,
+			ValueAvg:  avg,
+		})
+	}
+	return []backend.Sensor{s}
+}
+
+// columnID stable, positive reading ID of a column from its label and
+// unit, so a restarted log with other columns keeps the IDs. seen
+// counts the columns of the same label and unit before, e.g. the
+// temperatures of two GPUs.
+func columnID(c Column, seen map[[2]string]int) int32 {
+	k := [2]string{c.Label, c.Unit}
+	n := seen[k]
+	seen[k]++
+
+	h := fnv.New32a()
+	h.Write([]byte(c.Label))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Unit))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(n)))
+	return int32(h.Sum32() &^ (1 << 31))
+}
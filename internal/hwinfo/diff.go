@@ -0,0 +1,110 @@
+package hwinfo
+
+import "math"
+
+// EventKind kind of change between two snapshots
+type EventKind int
+
+const (
+	// SensorAdded sensor appeared, e.g. a USB drive was plugged in
+	SensorAdded EventKind = iota
+	// SensorRemoved sensor disappeared
+	SensorRemoved
+	// ReadingAdded reading appeared
+	ReadingAdded
+	// ReadingRemoved reading disappeared
+	ReadingRemoved
+	// ValueChanged reading value changed
+	ValueChanged
+)
+
+func (k EventKind) String() string {
+	return [...]string{"SensorAdded", "SensorRemoved", "ReadingAdded", "ReadingRemoved", "ValueChanged"}[k]
+}
+
+// ReadingKey identifies a reading across snapshots,
+// Sensor.ID together with Reading.ID
+type ReadingKey struct {
+	SensorID  string
+	ReadingID int32
+}
+
+// Event a change between two snapshots, ReadingID and values
+// are only set for reading events
+type Event struct {
+	Kind      EventKind
+	SensorID  string
+	ReadingID int32
+	// Name sensor name or reading label
+	Name     string
+	OldValue float64
+	NewValue float64
+}
+
+type snapshotIndex struct {
+	sensorIDs []string
+	sensors   map[string]Sensor
+	keys      []ReadingKey
+	readings  map[ReadingKey]Reading
+}
+
+func indexSnapshot(s *SharedMemory) snapshotIndex {
+	idx := snapshotIndex{
+		sensors:  make(map[string]Sensor),
+		readings: make(map[ReadingKey]Reading),
+	}
+	if s == nil {
+		return idx
+	}
+	for sens := range s.IterSensors() {
+		id := sens.ID()
+		idx.sensorIDs = append(idx.sensorIDs, id)
+		idx.sensors[id] = sens
+	}
+	for r := range s.IterReadings() {
+		// sensor index is within bounds, checked by NewSharedMemory
+		k := ReadingKey{SensorID: idx.sensorIDs[r.SensorIndex()], ReadingID: r.ID()}
+		idx.keys = append(idx.keys, k)
+		idx.readings[k] = r
+	}
+	return idx
+}
+
+// Diff compares two snapshots and returns the changes from old to new,
+// ordered as removals followed by additions and value changes, each in
+// snapshot order. A nil old reports everything in new as added.
+func Diff(old, new *SharedMemory) []Event {
+	o := indexSnapshot(old)
+	n := indexSnapshot(new)
+
+	var events []Event
+	for _, k := range o.keys {
+		if _, ok := n.readings[k]; !ok {
+			r := o.readings[k]
+			events = append(events, Event{Kind: ReadingRemoved, SensorID: k.SensorID, ReadingID: k.ReadingID, Name: r.LabelOrig(), OldValue: r.Value()})
+		}
+	}
+	for _, id := range o.sensorIDs {
+		if _, ok := n.sensors[id]; !ok {
+			s := o.sensors[id]
+			events = append(events, Event{Kind: SensorRemoved, SensorID: id, Name: s.NameOrig()})
+		}
+	}
+	for _, id := range n.sensorIDs {
+		if _, ok := o.sensors[id]; !ok {
+			s := n.sensors[id]
+			events = append(events, Event{Kind: SensorAdded, SensorID: id, Name: s.NameOrig()})
+		}
+	}
+	for _, k := range n.keys {
+		r := n.readings[k]
+		or, ok := o.readings[k]
+		switch {
+		case !ok:
+			events = append(events, Event{Kind: ReadingAdded, SensorID: k.SensorID, ReadingID: k.ReadingID, Name: r.LabelOrig(), NewValue: r.Value()})
+		case math.Float64bits(or.Value()) != math.Float64bits(r.Value()):
+			events = append(events, Event{Kind: ValueChanged, SensorID: k.SensorID, ReadingID: k.ReadingID, Name: r.LabelOrig(), OldValue: or.Value(), NewValue: r.Value()})
+		}
+	}
+	return events
+}
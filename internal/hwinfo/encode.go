@@ -0,0 +1,76 @@
+package hwinfo
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/util"
+)
+
+// SensorElement field values of a HWiNFO_SENSORS_SENSOR_ELEMENT
+type SensorElement struct {
+	SensorID   uint32
+	SensorInst uint32
+	NameOrig   string
+	NameUser   string
+}
+
+// ReadingElement field values of a HWiNFO_SENSORS_READING_ELEMENT
+type ReadingElement struct {
+	Type        ReadingType
+	SensorIndex uint32
+	ReadingID   uint32
+	LabelOrig   string
+	LabelUser   string
+	Unit        string
+	Value       float64
+	ValueMin    float64
+	ValueMax    float64
+	ValueAvg    float64
+}
+
+// Encode builds an active version 1 HWiNFO_SENSORS_SHARED_MEM2 in the
+// same layout HWiNFO writes, reusing dst when it has the capacity
+func Encode(dst []byte, pollTime uint64, sensors []SensorElement, readings []ReadingElement) []byte {
+	le := binary.LittleEndian
+	sensorOff := HeaderSize
+	readingOff := sensorOff + len(sensors)*SensorElementSize
+	n := readingOff + len(readings)*ReadingElementSize
+	if cap(dst) < n {
+		dst = make([]byte, n)
+	}
+	data := dst[:n]
+
+	copy(data[offSignature:], signatureActive)
+	le.PutUint32(data[offVersion:], 1)
+	le.PutUint32(data[offRevision:], 0)
+	le.PutUint64(data[offPollTime:], pollTime)
+	le.PutUint32(data[offOffsetOfSensorSection:], uint32(sensorOff))
+	le.PutUint32(data[offSizeOfSensorElement:], SensorElementSize)
+	le.PutUint32(data[offNumSensorElements:], uint32(len(sensors)))
+	le.PutUint32(data[offOffsetOfReadingSection:], uint32(readingOff))
+	le.PutUint32(data[offSizeOfReadingElement:], ReadingElementSize)
+	le.PutUint32(data[offNumReadingElements:], uint32(len(readings)))
+
+	for i, s := range sensors {
+		e := data[sensorOff+i*SensorElementSize:]
+		le.PutUint32(e[offSensorID:], s.SensorID)
+		le.PutUint32(e[offSensorInst:], s.SensorInst)
+		util.EncodeCString(e[offSensorNameOrig:offSensorNameOrig+stringLen], s.NameOrig)
+		util.EncodeCString(e[offSensorNameUser:offSensorNameUser+stringLen], s.NameUser)
+	}
+	for i, r := range readings {
+		e := data[readingOff+i*ReadingElementSize:]
+		le.PutUint32(e[offReadingType:], uint32(r.Type))
+		le.PutUint32(e[offReadingSensorIndex:], r.SensorIndex)
+		le.PutUint32(e[offReadingID:], r.ReadingID)
+		util.EncodeCString(e[offReadingLabelOrig:offReadingLabelOrig+stringLen], r.LabelOrig)
+		util.EncodeCString(e[offReadingLabelUser:offReadingLabelUser+stringLen], r.LabelUser)
+		util.EncodeCString(e[offReadingUnit:offReadingUnit+unitStringLen], r.Unit)
+		le.PutUint64(e[offReadingValue:], math.Float64bits(r.Value))
+		le.PutUint64(e[offReadingValueMin:], math.Float64bits(r.ValueMin))
+		le.PutUint64(e[offReadingValueMax:], math.Float64bits(r.ValueMax))
+		le.PutUint64(e[offReadingValueAvg:], math.Float64bits(r.ValueAvg))
+	}
+	return data
+}
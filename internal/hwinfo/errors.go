@@ -0,0 +1,17 @@
+package hwinfo
+
+import "errors"
+
+var (
+	// ErrInactive HWiNFO is not running or shared memory support was
+	// switched off, the signature reads 'DEAD'
+	ErrInactive = errors.New("hwinfo: shared memory inactive")
+	// ErrTruncated the snapshot is shorter than its header describes,
+	// e.g. a segment captured while HWiNFO was resizing it
+	ErrTruncated = errors.New("hwinfo: shared memory truncated")
+	// ErrLayout the header or elements describe an impossible layout
+	ErrLayout = errors.New("hwinfo: invalid shared memory layout")
+	// ErrUnsupportedVersion the header advertises a major version
+	// this decoder does not know the layout of
+	ErrUnsupportedVersion = errors.New("hwinfo: unsupported shared memory version")
+)
@@ -1,39 +1,98 @@
 package hwinfo
 
-/*
-#include <windows.h>
-#include "hwisenssm2.h"
-*/
-import "C"
-
 import (
+	"context"
+	"encoding/binary"
 	"fmt"
-	"log"
+	"sync/atomic"
 	"time"
-	"unsafe"
 
-	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/shmem"
 	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/util"
 )
 
-// SharedMemory provides access to the HWiNFO shared memory
+// SharedMemory provides access to a snapshot of the HWiNFO shared memory,
+// safe for concurrent use. Snapshots read with ReadPooled share a pool of
+// buffers and are reference counted, see Retain and Release.
 type SharedMemory struct {
-	data  []byte
-	shmem C.PHWiNFO_SENSORS_SHARED_MEM2
+	data []byte
+
+	// pooled buffer backing data, nil when not pooled
+	buf  *[]byte
+	refs atomic.Int32
 }
 
-// ReadSharedMem reads data from HWiNFO shared memory
-// creating a copy of the data
-func ReadSharedMem() (*SharedMemory, error) {
-	data, err := shmem.ReadBytes()
-	if err != nil {
+const (
+	signatureActive   = "HWiS"
+	signatureInactive = "DEAD"
+	// 'DEAD' written as a multi-character constant, little endian
+	signatureInactiveLE = "DAED"
+)
+
+// NewSharedMemory decodes a snapshot of HWiNFO_SENSORS_SHARED_MEM2,
+// data is referenced, not copied. The header and element bounds are
+// validated so accessors never read outside of data, errors wrap
+// ErrInactive, ErrTruncated, ErrLayout or ErrUnsupportedVersion.
+func NewSharedMemory(data []byte) (*SharedMemory, error) {
+	if len(data) < HeaderSize {
+		return nil, fmt.Errorf("%w: %d bytes for header size %d", ErrTruncated, len(data), HeaderSize)
+	}
+	s := &SharedMemory{data: data}
+	if err := s.validate(); err != nil {
 		return nil, err
 	}
+	return s, nil
+}
+
+func (s *SharedMemory) validate() error {
+	switch sig := s.Signature(); sig {
+	case signatureActive:
+	case signatureInactive, signatureInactiveLE:
+		return ErrInactive
+	default:
+		return fmt.Errorf("%w: unknown signature %q", ErrLayout, sig)
+	}
+
+	if v := s.Version(); v < MinSupportedVersion || v > MaxSupportedVersion {
+		return fmt.Errorf("%w: version %d revision %d, supported versions %d to %d",
+			ErrUnsupportedVersion, v, s.Revision(), MinSupportedVersion, MaxSupportedVersion)
+	}
 
-	return &SharedMemory{
-		data:  append([]byte(nil), data...),
-		shmem: C.PHWiNFO_SENSORS_SHARED_MEM2(unsafe.Pointer(&data[0])),
-	}, nil
+	if err := s.validateSection("sensor", s.OffsetOfSensorSection(), s.SizeOfSensorElement(), s.NumSensorElements(), SensorElementSize); err != nil {
+		return err
+	}
+	if err := s.validateSection("reading", s.OffsetOfReadingSection(), s.SizeOfReadingElement(), s.NumReadingElements(), ReadingElementSize); err != nil {
+		return err
+	}
+
+	numSensors := uint64(s.NumSensorElements())
+	for i := 0; i < s.NumReadingElements(); i++ {
+		r := NewReading(s.dataForReading(i))
+		if r.SensorIndex() >= numSensors {
+			return fmt.Errorf("%w: reading %d sensor index %d out of range for %d sensors", ErrLayout, i, r.SensorIndex(), numSensors)
+		}
+	}
+	return nil
+}
+
+func (s *SharedMemory) validateSection(name string, offset, size, num, minSize int) error {
+	if num == 0 {
+		return nil
+	}
+	if offset < HeaderSize {
+		return fmt.Errorf("%w: %s section offset %d overlaps header", ErrLayout, name, offset)
+	}
+	if size < minSize {
+		return fmt.Errorf("%w: %s element size %d smaller than %d", ErrLayout, name, size, minSize)
+	}
+	if offset > len(s.data) || num > (len(s.data)-offset)/size {
+		return fmt.Errorf("%w: %d %s elements of %d bytes at offset %d exceed %d bytes", ErrTruncated, num, name, size, offset, len(s.data))
+	}
+	return nil
+}
+
+// Bytes raw HWiNFO_SENSORS_SHARED_MEM2 snapshot
+func (s *SharedMemory) Bytes() []byte {
+	return s.data
 }
 
 // Result for streamed shared memory updates
@@ -42,83 +101,131 @@ type Result struct {
 	Err   error
 }
 
-func readAndSend(ch chan<- Result) {
-	shmem, err := ReadSharedMem()
-	ch <- Result{Shmem: shmem, Err: err}
+// DefaultInterval between shared memory reads
+const DefaultInterval = time.Second
+
+// StreamOptions configures StreamSharedMem
+type StreamOptions struct {
+	// Interval between reads, DefaultInterval when zero
+	Interval time.Duration
+	// SkipUnchanged only delivers a snapshot when its PollTime
+	// advanced past the last delivered one, errors are always delivered
+	SkipUnchanged bool
 }
 
+// ReadFunc reads a snapshot of shared memory, e.g. ReadSharedMem.
+// Receivers of streamed snapshots own them and Release them when done.
+type ReadFunc func() (*SharedMemory, error)
+
 // StreamSharedMem delivers shared memory hardware sensors updates
-// over a channel
-func StreamSharedMem() <-chan Result {
+// over a channel, the channel is closed once ctx is done
+func StreamSharedMem(ctx context.Context, opts StreamOptions) <-chan Result {
+	return Stream(ctx, opts, ReadSharedMem)
+}
+
+// Stream delivers snapshots returned by read over a channel,
+// the channel is closed once ctx is done
+func Stream(ctx context.Context, opts StreamOptions, read ReadFunc) <-chan Result {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
 	ch := make(chan Result)
 	go func() {
-		readAndSend(ch)
-		// TODO: don't use time.Tick, cancellable?
-		for range time.Tick(1 * time.Second) {
-			readAndSend(ch)
+		defer close(ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var lastPoll uint64
+		for {
+			shmem, err := read()
+			switch {
+			case err != nil:
+				lastPoll = 0
+			case opts.SkipUnchanged && lastPoll != 0 && shmem.PollTime() <= lastPoll:
+				shmem.Release()
+				shmem = nil
+			default:
+				lastPoll = shmem.PollTime()
+			}
+			if shmem != nil || err != nil {
+				select {
+				case ch <- Result{Shmem: shmem, Err: err}:
+				case <-ctx.Done():
+					shmem.Release()
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 	return ch
 }
 
+func (s *SharedMemory) dword(off int) uint32 {
+	return binary.LittleEndian.Uint32(s.data[off:])
+}
+
 // Signature "HWiS" if active, 'DEAD' when inactive
 func (s *SharedMemory) Signature() string {
-	return util.DecodeCharPtr(unsafe.Pointer(&s.shmem.dwSignature), C.sizeof_DWORD)
+	return util.DecodeCString(s.data[offSignature : offSignature+4])
 }
 
-// Version v1 is latest
+// Version major version of the layout
 func (s *SharedMemory) Version() int {
-	return int(s.shmem.dwVersion)
+	return int(s.dword(offVersion))
 }
 
 // Revision revision of version
 func (s *SharedMemory) Revision() int {
-	return int(s.shmem.dwRevision)
+	return int(s.dword(offRevision))
 }
 
 // PollTime last polling time
 func (s *SharedMemory) PollTime() uint64 {
-	addr := unsafe.Pointer(uintptr(unsafe.Pointer(&s.shmem.dwRevision)) + C.sizeof_DWORD)
-	return uint64(*(*C.__time64_t)(addr))
+	return binary.LittleEndian.Uint64(s.data[offPollTime:])
 }
 
 // OffsetOfSensorSection offset of the Sensor section from beginning of HWiNFO_SENSORS_SHARED_MEM2
 func (s *SharedMemory) OffsetOfSensorSection() int {
-	return int(s.shmem.dwOffsetOfSensorSection)
+	return int(s.dword(offOffsetOfSensorSection))
 }
 
 // SizeOfSensorElement size of each sensor element = sizeof( HWiNFO_SENSORS_SENSOR_ELEMENT )
 func (s *SharedMemory) SizeOfSensorElement() int {
-	return int(s.shmem.dwSizeOfSensorElement)
+	return int(s.dword(offSizeOfSensorElement))
 }
 
 // NumSensorElements number of sensor elements
 func (s *SharedMemory) NumSensorElements() int {
-	return int(s.shmem.dwNumSensorElements)
+	return int(s.dword(offNumSensorElements))
 }
 
 // OffsetOfReadingSection offset of the Reading section from beginning of HWiNFO_SENSORS_SHARED_MEM2
 func (s *SharedMemory) OffsetOfReadingSection() int {
-	return int(s.shmem.dwOffsetOfReadingSection)
+	return int(s.dword(offOffsetOfReadingSection))
 }
 
 // SizeOfReadingElement size of each Reading element = sizeof( HWiNFO_SENSORS_READING_ELEMENT )
 func (s *SharedMemory) SizeOfReadingElement() int {
-	return int(s.shmem.dwSizeOfReadingElement)
+	return int(s.dword(offSizeOfReadingElement))
 }
 
 // NumReadingElements number of Reading elements
 func (s *SharedMemory) NumReadingElements() int {
-	return int(s.shmem.dwNumReadingElements)
+	return int(s.dword(offNumReadingElements))
 }
 
-func (s *SharedMemory) dataForSensor(pos int) ([]byte, error) {
-	if pos >= s.NumSensorElements() {
-		return nil, fmt.Errorf("dataForSensor pos out of range, %d for size %d", pos, s.NumSensorElements())
-	}
+// dataForSensor pos must be < NumSensorElements, bounds are checked by validate
+func (s *SharedMemory) dataForSensor(pos int) []byte {
 	start := s.OffsetOfSensorSection() + (pos * s.SizeOfSensorElement())
 	end := start + s.SizeOfSensorElement()
-	return s.data[start:end], nil
+	return s.data[start:end]
 }
 
 // IterSensors iterate over each sensor
@@ -126,24 +233,18 @@ func (s *SharedMemory) IterSensors() <-chan Sensor {
 	ch := make(chan Sensor)
 	go func() {
 		for i := 0; i < s.NumSensorElements(); i++ {
-			data, err := s.dataForSensor(i)
-			if err != nil {
-				log.Fatalf("TODO: failed to read dataForSensor: %v", err)
-			}
-			ch <- NewSensor(data)
+			ch <- NewSensor(s.dataForSensor(i))
 		}
 		close(ch)
 	}()
 	return ch
 }
 
-func (s *SharedMemory) dataForReading(pos int) ([]byte, error) {
-	if pos >= s.NumReadingElements() {
-		return nil, fmt.Errorf("dataForReading pos out of range, %d for size %d", pos, s.NumSensorElements())
-	}
+// dataForReading pos must be < NumReadingElements, bounds are checked by validate
+func (s *SharedMemory) dataForReading(pos int) []byte {
 	start := s.OffsetOfReadingSection() + (pos * s.SizeOfReadingElement())
 	end := start + s.SizeOfReadingElement()
-	return s.data[start:end], nil
+	return s.data[start:end]
 }
 
 // IterReadings iterate over each sensor
@@ -151,11 +252,7 @@ func (s *SharedMemory) IterReadings() <-chan Reading {
 	ch := make(chan Reading)
 	go func() {
 		for i := 0; i < s.NumReadingElements(); i++ {
-			data, err := s.dataForReading(i)
-			if err != nil {
-				log.Fatalf("TODO: failed to read dataForReading: %v", err)
-			}
-			ch <- NewReading(data)
+			ch <- NewReading(s.dataForReading(i))
 		}
 		close(ch)
 	}()
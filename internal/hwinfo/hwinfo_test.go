@@ -0,0 +1,331 @@
+package hwinfo
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+type testReading struct {
+	typ                   ReadingType
+	sensorIndex, id       uint32
+	labelOrig, labelUser  string
+	unit                  string
+	value, vmin, max, avg float64
+}
+
+// putString NUL pads s to the length of b
+func putString(b []byte, s string) {
+	n := copy(b, s)
+	for i := n; i < len(b); i++ {
+		b[i] = 0
+	}
+}
+
+func buildSharedMem(sensors [][2]string, readings []testReading) []byte {
+	return buildSharedMemPadded(0, sensors, readings)
+}
+
+// buildSharedMemPadded grows every element by pad bytes of 0xff,
+// as a newer HWiNFO appending fields would
+func buildSharedMemPadded(pad int, sensors [][2]string, readings []testReading) []byte {
+	le := binary.LittleEndian
+	sensorSize := SensorElementSize + pad
+	readingSize := ReadingElementSize + pad
+	sensorOff := HeaderSize
+	readingOff := sensorOff + len(sensors)*sensorSize
+	data := make([]byte, readingOff+len(readings)*readingSize)
+	for i := HeaderSize; i < len(data); i++ {
+		data[i] = 0xff
+	}
+
+	copy(data[offSignature:], "HWiS")
+	le.PutUint32(data[offVersion:], 1)
+	le.PutUint32(data[offRevision:], 2)
+	le.PutUint64(data[offPollTime:], 1600000000)
+	le.PutUint32(data[offOffsetOfSensorSection:], uint32(sensorOff))
+	le.PutUint32(data[offSizeOfSensorElement:], uint32(sensorSize))
+	le.PutUint32(data[offNumSensorElements:], uint32(len(sensors)))
+	le.PutUint32(data[offOffsetOfReadingSection:], uint32(readingOff))
+	le.PutUint32(data[offSizeOfReadingElement:], uint32(readingSize))
+	le.PutUint32(data[offNumReadingElements:], uint32(len(readings)))
+
+	for i, s := range sensors {
+		e := data[sensorOff+i*sensorSize:]
+		le.PutUint32(e[offSensorID:], 0xf0000300)
+		le.PutUint32(e[offSensorInst:], uint32(i))
+		putString(e[offSensorNameOrig:offSensorNameOrig+stringLen], s[0])
+		putString(e[offSensorNameUser:offSensorNameUser+stringLen], s[1])
+	}
+	for i, r := range readings {
+		e := data[readingOff+i*readingSize:]
+		le.PutUint32(e[offReadingType:], uint32(r.typ))
+		le.PutUint32(e[offReadingSensorIndex:], r.sensorIndex)
+		le.PutUint32(e[offReadingID:], r.id)
+		putString(e[offReadingLabelOrig:offReadingLabelOrig+stringLen], r.labelOrig)
+		putString(e[offReadingLabelUser:offReadingLabelUser+stringLen], r.labelUser)
+		putString(e[offReadingUnit:offReadingUnit+unitStringLen], r.unit)
+		le.PutUint64(e[offReadingValue:], math.Float64bits(r.value))
+		le.PutUint64(e[offReadingValueMin:], math.Float64bits(r.vmin))
+		le.PutUint64(e[offReadingValueMax:], math.Float64bits(r.max))
+		le.PutUint64(e[offReadingValueAvg:], math.Float64bits(r.avg))
+	}
+	return data
+}
+
+func TestSharedMemory(t *testing.T) {
+	data := buildSharedMem(
+		[][2]string{{"CPU [#0]: AMD Ryzen", "CPU"}, {"GPU [#0]", "GPU"}},
+		[]testReading{
+			{ReadingTypeTemp, 0, 7, "CPU Package", "Package", "\xb0C", 54.5, 30, 80, 50},
+			{ReadingTypeFan, 1, 1000, "GPU Fan", "", "RPM", 1200, 0, 2400, 900},
+		},
+	)
+
+	shmem, err := NewSharedMemory(data)
+	if err != nil {
+		t.Fatalf("NewSharedMemory: %v", err)
+	}
+	if got := shmem.Signature(); got != "HWiS" {
+		t.Errorf("Signature = %q, want HWiS", got)
+	}
+	if shmem.Version() != 1 || shmem.Revision() != 2 {
+		t.Errorf("Version/Revision = %d/%d, want 1/2", shmem.Version(), shmem.Revision())
+	}
+	if got := shmem.PollTime(); got != 1600000000 {
+		t.Errorf("PollTime = %d", got)
+	}
+
+	var sensors []Sensor
+	for s := range shmem.IterSensors() {
+		sensors = append(sensors, s)
+	}
+	if len(sensors) != 2 {
+		t.Fatalf("got %d sensors, want 2", len(sensors))
+	}
+	if got := sensors[1].ID(); got != "402653260801" {
+		t.Errorf("sensor ID = %s", got)
+	}
+	if sensors[0].NameOrig() != "CPU [#0]: AMD Ryzen" || sensors[0].NameUser() != "CPU" {
+		t.Errorf("sensor names = %q, %q", sensors[0].NameOrig(), sensors[0].NameUser())
+	}
+
+	var readings []Reading
+	for r := range shmem.IterReadings() {
+		readings = append(readings, r)
+	}
+	if len(readings) != 2 {
+		t.Fatalf("got %d readings, want 2", len(readings))
+	}
+	r := readings[0]
+	if r.Type() != ReadingTypeTemp || r.SensorIndex() != 0 || r.ID() != 7 {
+		t.Errorf("reading = type %v, sensor %d, id %d", r.Type(), r.SensorIndex(), r.ID())
+	}
+	if r.LabelOrig() != "CPU Package" || r.LabelUser() != "Package" {
+		t.Errorf("reading labels = %q, %q", r.LabelOrig(), r.LabelUser())
+	}
+	if r.Unit() != "°C" {
+		t.Errorf("Unit = %q, want ISO8859-1 decoded °C", r.Unit())
+	}
+	if r.Value() != 54.5 || r.ValueMin() != 30 || r.ValueMax() != 80 || r.ValueAvg() != 50 {
+		t.Errorf("values = %v %v %v %v", r.Value(), r.ValueMin(), r.ValueMax(), r.ValueAvg())
+	}
+	if readings[1].SensorIndex() != 1 || readings[1].Unit() != "RPM" {
+		t.Errorf("second reading = sensor %d, unit %q", readings[1].SensorIndex(), readings[1].Unit())
+	}
+}
+
+func TestNewSharedMemoryShort(t *testing.T) {
+	if _, err := NewSharedMemory(make([]byte, HeaderSize-1)); err == nil {
+		t.Fatal("expected error for short buffer")
+	}
+}
+
+func TestNewSharedMemoryErrors(t *testing.T) {
+	valid := func() []byte {
+		return buildSharedMem(
+			[][2]string{{"CPU", ""}},
+			[]testReading{{ReadingTypeTemp, 0, 1, "Core", "", "C", 1, 1, 1, 1}},
+		)
+	}
+	le := binary.LittleEndian
+
+	tests := []struct {
+		name   string
+		modify func([]byte) []byte
+		want   error
+	}{
+		{"inactive", func(b []byte) []byte { copy(b, "DEAD"); return b }, ErrInactive},
+		{"unknown version", func(b []byte) []byte { le.PutUint32(b[offVersion:], 3); return b }, ErrUnsupportedVersion},
+		{"bad signature", func(b []byte) []byte { copy(b, "ABCD"); return b }, ErrLayout},
+		{"cut short", func(b []byte) []byte { return b[:len(b)-1] }, ErrTruncated},
+		{"element count", func(b []byte) []byte { le.PutUint32(b[offNumReadingElements:], 1<<31); return b }, ErrTruncated},
+		{"element size", func(b []byte) []byte { le.PutUint32(b[offSizeOfSensorElement:], 8); return b }, ErrLayout},
+		{"section offset", func(b []byte) []byte { le.PutUint32(b[offOffsetOfSensorSection:], 4); return b }, ErrLayout},
+		{"sensor index", func(b []byte) []byte {
+			le.PutUint32(b[HeaderSize+SensorElementSize+offReadingSensorIndex:], 3)
+			return b
+		}, ErrLayout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSharedMemory(tt.modify(valid()))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamSkipUnchanged(t *testing.T) {
+	polls := []uint64{1, 1, 2, 2, 3}
+	i := 0
+	read := func() (*SharedMemory, error) {
+		data := buildSharedMem(nil, nil)
+		binary.LittleEndian.PutUint64(data[offPollTime:], polls[i%len(polls)])
+		i++
+		return NewSharedMemory(data)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := Stream(ctx, StreamOptions{Interval: time.Millisecond, SkipUnchanged: true}, read)
+	for _, want := range []uint64{1, 2, 3} {
+		r := <-ch
+		if r.Err != nil || r.Shmem.PollTime() != want {
+			t.Fatalf("got poll time %d (err %v), want %d", r.Shmem.PollTime(), r.Err, want)
+		}
+	}
+	cancel()
+	for range ch {
+		// drain until closed
+	}
+}
+
+func TestDiff(t *testing.T) {
+	mustShmem := func(data []byte) *SharedMemory {
+		s, err := NewSharedMemory(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	old := mustShmem(buildSharedMem(
+		[][2]string{{"CPU", ""}, {"USB", ""}},
+		[]testReading{
+			{ReadingTypeTemp, 0, 1, "Core", "", "C", 40, 0, 0, 0},
+			{ReadingTypeTemp, 0, 2, "Package", "", "C", 50, 0, 0, 0},
+			{ReadingTypeTemp, 1, 1, "Drive", "", "C", 30, 0, 0, 0},
+		},
+	))
+	new := mustShmem(buildSharedMem(
+		[][2]string{{"CPU", ""}},
+		[]testReading{
+			{ReadingTypeTemp, 0, 1, "Core", "", "C", 41, 0, 0, 0},
+			{ReadingTypeTemp, 0, 3, "Tdie", "", "C", 45, 0, 0, 0},
+		},
+	))
+
+	got := Diff(old, new)
+	want := []struct {
+		kind EventKind
+		name string
+	}{
+		{ReadingRemoved, "Package"},
+		{ReadingRemoved, "Drive"},
+		{SensorRemoved, "USB"},
+		{ValueChanged, "Core"},
+		{ReadingAdded, "Tdie"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events %+v, want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Kind != w.kind || got[i].Name != w.name {
+			t.Errorf("event %d = %v %q, want %v %q", i, got[i].Kind, got[i].Name, w.kind, w.name)
+		}
+	}
+	if got[3].OldValue != 40 || got[3].NewValue != 41 {
+		t.Errorf("ValueChanged values = %v -> %v", got[3].OldValue, got[3].NewValue)
+	}
+
+	if n := len(Diff(nil, new)); n != 3 {
+		t.Errorf("Diff from nil = %d events, want 3 added", n)
+	}
+}
+
+func TestLargerElements(t *testing.T) {
+	data := buildSharedMemPadded(32,
+		[][2]string{{"CPU", "CPU"}, {"GPU", "GPU"}},
+		[]testReading{
+			{ReadingTypeTemp, 0, 1, "Core", "Core", "C", 40, 30, 50, 45},
+			{ReadingTypeFan, 1, 2, "Fan", "Fan", "RPM", 900, 800, 1000, 850},
+		},
+	)
+	binary.LittleEndian.PutUint32(data[offVersion:], 2)
+
+	shmem, err := NewSharedMemory(data)
+	if err != nil {
+		t.Fatalf("NewSharedMemory: %v", err)
+	}
+	var names []string
+	for s := range shmem.IterSensors() {
+		names = append(names, s.NameOrig())
+	}
+	if len(names) != 2 || names[1] != "GPU" {
+		t.Errorf("sensor names = %q", names)
+	}
+	var readings []Reading
+	for r := range shmem.IterReadings() {
+		readings = append(readings, r)
+	}
+	if len(readings) != 2 {
+		t.Fatalf("got %d readings, want 2", len(readings))
+	}
+	r := readings[1]
+	if r.SensorIndex() != 1 || r.LabelOrig() != "Fan" || r.Unit() != "RPM" || r.ValueAvg() != 850 {
+		t.Errorf("reading = sensor %d %q %q avg %v", r.SensorIndex(), r.LabelOrig(), r.Unit(), r.ValueAvg())
+	}
+}
+
+func TestReadPooled(t *testing.T) {
+	var poll uint64
+	read := func(dst []byte) ([]byte, error) {
+		poll++
+		return Encode(dst, poll, []SensorElement{{SensorID: 1, NameOrig: "CPU"}}, []ReadingElement{{LabelOrig: "Core", Value: float64(poll)}}), nil
+	}
+
+	held, err := ReadPooled(read)
+	if err != nil {
+		t.Fatalf("ReadPooled: %v", err)
+	}
+	held.Retain()
+	held.Release()
+
+	// later reads must not reuse the bytes of a snapshot still held
+	for i := 0; i < 10; i++ {
+		s, err := ReadPooled(read)
+		if err != nil {
+			t.Fatalf("ReadPooled: %v", err)
+		}
+		s.Release()
+	}
+	if held.PollTime() != 1 {
+		t.Errorf("held snapshot PollTime = %d, want 1", held.PollTime())
+	}
+	for r := range held.IterReadings() {
+		if r.Value() != 1 {
+			t.Errorf("held reading value = %v, want 1", r.Value())
+		}
+	}
+	held.Release()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic releasing a released snapshot")
+		}
+	}()
+	held.Release()
+}
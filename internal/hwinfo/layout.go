@@ -0,0 +1,65 @@
+package hwinfo
+
+// Byte offsets of the packed structures declared in hwisenssm2.h
+// (#pragma pack(1), little endian, DWORD = 4 bytes, __time64_t = 8 bytes)
+//
+// Newer versions and revisions only append fields to the sensor and
+// reading elements, growing dwSizeOfSensorElement/dwSizeOfReadingElement.
+// Elements are addressed with the advertised sizes and only the fields
+// below are read, anything past them is ignored.
+
+// Shared memory major versions with a known layout
+const (
+	MinSupportedVersion = 1
+	MaxSupportedVersion = 2
+)
+
+// HWiNFO_SENSORS_SHARED_MEM2
+const (
+	offSignature              = 0
+	offVersion                = 4
+	offRevision               = 8
+	offPollTime               = 12
+	offOffsetOfSensorSection  = 20
+	offSizeOfSensorElement    = 24
+	offNumSensorElements      = 28
+	offOffsetOfReadingSection = 32
+	offSizeOfReadingElement   = 36
+	offNumReadingElements     = 40
+
+	// HeaderSize size of HWiNFO_SENSORS_SHARED_MEM2
+	HeaderSize = 44
+)
+
+const (
+	stringLen     = 128 // HWiNFO_SENSORS_STRING_LEN2
+	unitStringLen = 16  // HWiNFO_UNIT_STRING_LEN
+)
+
+// HWiNFO_SENSORS_SENSOR_ELEMENT
+const (
+	offSensorID       = 0
+	offSensorInst     = 4
+	offSensorNameOrig = 8
+	offSensorNameUser = offSensorNameOrig + stringLen
+
+	// SensorElementSize size of HWiNFO_SENSORS_SENSOR_ELEMENT
+	SensorElementSize = offSensorNameUser + stringLen
+)
+
+// HWiNFO_SENSORS_READING_ELEMENT
+const (
+	offReadingType        = 0
+	offReadingSensorIndex = 4
+	offReadingID          = 8
+	offReadingLabelOrig   = 12
+	offReadingLabelUser   = offReadingLabelOrig + stringLen
+	offReadingUnit        = offReadingLabelUser + stringLen
+	offReadingValue       = offReadingUnit + unitStringLen
+	offReadingValueMin    = offReadingValue + 8
+	offReadingValueMax    = offReadingValueMin + 8
+	offReadingValueAvg    = offReadingValueMax + 8
+
+	// ReadingElementSize size of HWiNFO_SENSORS_READING_ELEMENT
+	ReadingElementSize = offReadingValueAvg + 8
+)
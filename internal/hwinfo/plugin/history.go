@@ -0,0 +1,191 @@
+package plugin
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+const (
+	// DefaultHistoryDepth samples kept per reading, a little more
+	// than a tile graph is wide
+	DefaultHistoryDepth = 128
+	// DefaultHistoryMaxBytes bound of the memory held by samples
+	DefaultHistoryMaxBytes = 4 << 20
+)
+
+// HistoryOptions configure the reading history of a Service
+type HistoryOptions struct {
+	// Depth samples kept per reading, no history when 0
+	Depth int
+	// MaxBytes bound of the memory held by samples of all readings,
+	// fewer than Depth samples are kept per reading when exceeded
+	MaxBytes int
+}
+
+// DefaultHistoryOptions history kept unless configured otherwise
+var DefaultHistoryOptions = HistoryOptions{Depth: DefaultHistoryDepth, MaxBytes: DefaultHistoryMaxBytes}
+
+type sample struct {
+	unixNano int64
+	value    float64
+}
+
+// sampleSize bytes held by a sample
+const sampleSize = 16
+
+// ring the newest samples of a reading, oldest at start
+type ring struct {
+	samples []sample
+	start   int
+}
+
+func (r *ring) add(s sample, depth int) {
+	if len(r.samples) < depth {
+		r.samples = append(r.samples, s)
+		return
+	}
+	r.samples[r.start] = s
+	r.start = (r.start + 1) % len(r.samples)
+}
+
+// ordered samples oldest first
+func (r *ring) ordered() []sample {
+	return append(append([]sample(nil), r.samples[r.start:]...), r.samples[:r.start]...)
+}
+
+// resize keeps the newest depth samples
+func (r *ring) resize(depth int) {
+	s := r.ordered()
+	if len(s) > depth {
+		s = s[len(s)-depth:]
+	}
+	r.samples = append(make([]sample, 0, depth), s...)
+	r.start = 0
+}
+
+// history rings of all readings of the latest snapshot
+type history struct {
+	mu    sync.RWMutex
+	opts  HistoryOptions
+	depth int
+	rings map[hwinfo.ReadingKey]*ring
+	// pollTime of the last recorded snapshot
+	pollTime uint64
+}
+
+func newHistory(opts HistoryOptions) *history {
+	return &history{opts: opts, rings: make(map[hwinfo.ReadingKey]*ring)}
+}
+
+// depthFor the samples kept per reading for n readings
+func (h *history) depthFor(n int) int {
+	depth := h.opts.Depth
+	if h.opts.MaxBytes > 0 && n > 0 {
+		if fit := h.opts.MaxBytes / (n * sampleSize); fit < depth {
+			depth = fit
+		}
+	}
+	return depth
+}
+
+// record the readings of shmem taken at t, once per HWiNFO poll.
+// Readings missing from shmem are forgotten.
+func (h *history) record(t time.Time, shmem *hwinfo.SharedMemory) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.opts.Depth <= 0 || shmem.PollTime() == h.pollTime {
+		return
+	}
+	h.pollTime = shmem.PollTime()
+
+	var sensorIDs []string
+	for sens := range shmem.IterSensors() {
+		sensorIDs = append(sensorIDs, sens.ID())
+	}
+	values := make(map[hwinfo.ReadingKey]float64)
+	for r := range shmem.IterReadings() {
+		// out of range indexes are reported by the snapshot index
+		if idx := int(r.SensorIndex()); idx < len(sensorIDs) {
+			values[hwinfo.ReadingKey{SensorID: sensorIDs[idx], ReadingID: r.ID()}] = r.Value()
+		}
+	}
+
+	for k := range h.rings {
+		if _, ok := values[k]; !ok {
+			delete(h.rings, k)
+		}
+	}
+	depth := h.depthFor(len(values))
+	if depth != h.depth {
+		for _, r := range h.rings {
+			r.resize(depth)
+		}
+		h.depth = depth
+	}
+	if depth <= 0 {
+		return
+	}
+	s := sample{unixNano: t.UnixNano()}
+	for k, v := range values {
+		r, ok := h.rings[k]
+		if !ok {
+			r = &ring{samples: make([]sample, 0, depth)}
+			h.rings[k] = r
+		}
+		s.value = v
+		r.add(s, depth)
+	}
+}
+
+// samples of key at or after since, at most the newest maxPoints
+func (h *history) samples(key hwinfo.ReadingKey, since time.Time, maxPoints int) ([]hwsensorsservice.Sample, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.opts.Depth <= 0 {
+		return nil, hwsensorsservice.ErrNoHistory
+	}
+	r, ok := h.rings[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s:%d", hwsensorsservice.ErrReadingNotFound, key.SensorID, key.ReadingID)
+	}
+	ordered := r.ordered()
+	if !since.IsZero() {
+		sinceNano := since.UnixNano()
+		for len(ordered) > 0 && ordered[0].unixNano < sinceNano {
+			ordered = ordered[1:]
+		}
+	}
+	if maxPoints > 0 && len(ordered) > maxPoints {
+		ordered = ordered[len(ordered)-maxPoints:]
+	}
+	samples := make([]hwsensorsservice.Sample, 0, len(ordered))
+	for _, s := range ordered {
+		samples = append(samples, hwsensorsservice.Sample{Time: time.Unix(0, s.unixNano), Value: s.value})
+	}
+	return samples, nil
+}
+
+// HistoryUsage readings with history, samples held and their memory
+type HistoryUsage struct {
+	Readings int
+	Samples  int
+	Bytes    int
+}
+
+func (h *history) usage() HistoryUsage {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	u := HistoryUsage{Readings: len(h.rings)}
+	for _, r := range h.rings {
+		u.Samples += len(r.samples)
+		u.Bytes += cap(r.samples) * sampleSize
+	}
+	return u
+}
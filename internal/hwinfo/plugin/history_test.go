@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+// feed sends a snapshot per poll time with both readings at value v
+func feed(t *testing.T, s *Service, pollTimes []uint64) {
+	t.Helper()
+	sensors := []hwinfo.SensorElement{{SensorID: 0xf0000100, NameOrig: "CPU"}}
+	ch := make(chan hwinfo.Result, len(pollTimes))
+	for _, pt := range pollTimes {
+		readings := []hwinfo.ReadingElement{
+			{Type: hwinfo.ReadingTypeTemp, ReadingID: 1, LabelOrig: "Core", Value: float64(pt)},
+			{Type: hwinfo.ReadingTypeFan, ReadingID: 2, LabelOrig: "Fan", Value: float64(pt) * 10},
+		}
+		shmem, err := hwinfo.NewSharedMemory(hwinfo.Encode(nil, pt, sensors, readings))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ch <- hwinfo.Result{Shmem: shmem}
+	}
+	close(ch)
+	s.streamch = ch
+	for range pollTimes {
+		if err := s.Recv(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	s := NewService(nil)
+	s.KeepHistory(HistoryOptions{Depth: 4})
+	start := time.Now()
+	// a repeated poll time is the same HWiNFO poll
+	feed(t, s, []uint64{1, 2, 2, 3, 4, 5, 6})
+
+	p := &Plugin{Service: s}
+	sensors, err := p.Sensors(context.Background())
+	if err != nil || len(sensors) != 1 {
+		t.Fatalf("Sensors = %v, %v", sensors, err)
+	}
+	key := hwsensorsservice.ReadingKey{SensorID: sensors[0].ID(), ReadingID: 1}
+
+	samples, err := p.History(context.Background(), key, time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []float64
+	for _, sm := range samples {
+		got = append(got, sm.Value)
+		if sm.Time.Before(start.Truncate(time.Millisecond)) {
+			t.Errorf("sample time %v before start %v", sm.Time, start)
+		}
+	}
+	if want := []float64{3, 4, 5, 6}; !equalValues(got, want) {
+		t.Errorf("history = %v, want %v", got, want)
+	}
+
+	samples, _ = p.History(context.Background(), key, time.Time{}, 2)
+	if len(samples) != 2 || samples[0].Value != 5 || samples[1].Value != 6 {
+		t.Errorf("history of 2 points = %v", samples)
+	}
+	samples, _ = p.History(context.Background(), key, time.Now().Add(time.Hour), 0)
+	if len(samples) != 0 {
+		t.Errorf("history since the future = %v", samples)
+	}
+
+	_, err = p.History(context.Background(), hwsensorsservice.ReadingKey{SensorID: key.SensorID, ReadingID: 9}, time.Time{}, 0)
+	if !errors.Is(err, hwsensorsservice.ErrReadingNotFound) {
+		t.Errorf("unknown reading err = %v", err)
+	}
+}
+
+func TestHistoryMaxBytes(t *testing.T) {
+	s := NewService(nil)
+	// room for 3 samples of each of the 2 readings
+	s.KeepHistory(HistoryOptions{Depth: 100, MaxBytes: 2 * 3 * sampleSize})
+	feed(t, s, []uint64{1, 2, 3, 4, 5})
+
+	u := s.HistoryUsage()
+	if u.Readings != 2 || u.Samples != 6 || u.Bytes > 2*3*sampleSize {
+		t.Errorf("usage = %+v", u)
+	}
+
+	s = NewService(nil)
+	s.KeepHistory(HistoryOptions{})
+	feed(t, s, []uint64{1})
+	if _, err := s.History(hwinfo.ReadingKey{}, time.Time{}, 0); !errors.Is(err, hwsensorsservice.ErrNoHistory) {
+		t.Errorf("disabled history err = %v", err)
+	}
+}
+
+func equalValues(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
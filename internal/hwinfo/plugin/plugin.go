@@ -1,6 +1,11 @@
 package plugin
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
 	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
 )
@@ -11,32 +16,44 @@ type Plugin struct {
 }
 
 // PollTime implementation for plugin
-func (p *Plugin) PollTime() (uint64, error) {
+func (p *Plugin) PollTime(ctx context.Context) (uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
 	shmem, err := p.Service.Shmem()
 	if err != nil {
-		return 0, err
+		return 0, serviceError(err)
 	}
+	defer shmem.Release()
 	return shmem.PollTime(), nil
 }
 
 // Sensors implementation for plugin
-func (p *Plugin) Sensors() ([]hwsensorsservice.Sensor, error) {
+func (p *Plugin) Sensors(ctx context.Context) ([]hwsensorsservice.Sensor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	shmem, err := p.Service.Shmem()
 	if err != nil {
-		return nil, err
+		return nil, serviceError(err)
 	}
+	defer shmem.Release()
 	var sensors []hwsensorsservice.Sensor
 	for s := range shmem.IterSensors() {
-		sensors = append(sensors, &sensor{s})
+		// cloned, callers may keep sensors past the snapshot
+		sensors = append(sensors, &sensor{s.Clone()})
 	}
 	return sensors, nil
 }
 
 // ReadingsForSensorID implementation for plugin
-func (p *Plugin) ReadingsForSensorID(id string) ([]hwsensorsservice.Reading, error) {
+func (p *Plugin) ReadingsForSensorID(ctx context.Context, id string) ([]hwsensorsservice.Reading, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	res, err := p.Service.ReadingsBySensorID(id)
 	if err != nil {
-		return nil, err
+		return nil, serviceError(err)
 	}
 	var readings []hwsensorsservice.Reading
 	for _, r := range res {
@@ -45,6 +62,201 @@ func (p *Plugin) ReadingsForSensorID(id string) ([]hwsensorsservice.Reading, err
 	return readings, nil
 }
 
+// ReadingsByKeys implementation for plugin, all readings are
+// from the same snapshot
+func (p *Plugin) ReadingsByKeys(ctx context.Context, keys []hwsensorsservice.ReadingKey) ([]hwsensorsservice.ReadingResult, error) {
+	_, results, err := p.readingsByKeys(ctx, keys)
+	return results, err
+}
+
+// readingsByKeys ReadingsByKeys along with the poll time of the
+// snapshot the readings are from
+func (p *Plugin) readingsByKeys(ctx context.Context, keys []hwsensorsservice.ReadingKey) (uint64, []hwsensorsservice.ReadingResult, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, nil, err
+	}
+	ids := make([]string, 0, len(keys))
+	for _, k := range keys {
+		ids = append(ids, k.SensorID)
+	}
+	pollTime, res, err := p.Service.readingsBySensorIDs(ids)
+	if err != nil {
+		return 0, nil, serviceError(err)
+	}
+	return pollTime, hwsensorsservice.LookupReadings(keys, func(id string) ([]hwsensorsservice.Reading, error) {
+		var readings []hwsensorsservice.Reading
+		for _, r := range res[id] {
+			readings = append(readings, &reading{r})
+		}
+		return readings, nil
+	}), nil
+}
+
+// Subscribe implements hwsensorsservice.Subscriber, an update is read
+// whenever the service receives a snapshot of a new poll instead of
+// polling for it
+func (p *Plugin) Subscribe(ctx context.Context, keys []hwsensorsservice.ReadingKey, minInterval time.Duration) (<-chan hwsensorsservice.Update, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	polls, stop := p.Service.Polls()
+	ch := make(chan hwsensorsservice.Update)
+	go func() {
+		defer close(ch)
+		defer stop()
+
+		var last uint64
+		var sent time.Time
+		for {
+			if wait := minInterval - time.Since(sent); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+			// failed reads, e.g. HWiNFO closed, are skipped until
+			// the next snapshot
+			if u, err := p.update(ctx, keys); err == nil && u.PollTime != last {
+				select {
+				case ch <- u:
+				case <-ctx.Done():
+					return
+				}
+				last, sent = u.PollTime, time.Now()
+			}
+			select {
+			case <-polls:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// update the readings of keys from the current snapshot
+func (p *Plugin) update(ctx context.Context, keys []hwsensorsservice.ReadingKey) (hwsensorsservice.Update, error) {
+	pollTime, results, err := p.readingsByKeys(ctx, keys)
+	if err != nil {
+		return hwsensorsservice.Update{}, err
+	}
+	u := hwsensorsservice.Update{PollTime: pollTime, Readings: make(map[hwsensorsservice.ReadingKey]hwsensorsservice.Reading, len(keys))}
+	for _, res := range results {
+		if res.Err != nil {
+			u.Missing = append(u.Missing, res.Key)
+		} else {
+			u.Readings[res.Key] = res.Reading
+		}
+	}
+	return u, nil
+}
+
+// Status implementation for plugin, the state follows the shared
+// memory signature
+func (p *Plugin) Status(ctx context.Context) (hwsensorsservice.Status, error) {
+	if err := ctx.Err(); err != nil {
+		return hwsensorsservice.Status{}, err
+	}
+	s := p.Service
+	s.mu.RLock()
+	st := hwsensorsservice.Status{Backend: hwsensorsservice.BackendHWiNFO, LastRead: s.lastRead}
+	if s.lastErr != nil {
+		st.LastError = s.lastErr.Error()
+	}
+	err := s.err
+	s.mu.RUnlock()
+
+	switch {
+	case errors.Is(err, hwinfo.ErrInactive):
+		st.State = hwsensorsservice.StateDead
+	case layoutError(err):
+		// HWiNFO is running, its shared memory cannot be decoded
+		st.State = hwsensorsservice.StateUnknown
+	case err != nil:
+		// opening the shared memory failed, HWiNFO is not running
+		st.State = hwsensorsservice.StateNotFound
+	}
+	if err != nil {
+		return st, nil
+	}
+
+	sn, err := s.acquire()
+	if err != nil {
+		// nothing received yet
+		return st, nil
+	}
+	defer sn.shmem.Release()
+	st.State = hwsensorsservice.StateActive
+	st.Version, st.Revision = sn.shmem.Version(), sn.shmem.Revision()
+	st.PollTime = sn.shmem.PollTime()
+	if err := sn.index(); err != nil {
+		st.LastError = err.Error()
+		return st, nil
+	}
+	st.Sensors = len(sn.sensorIDByIdx)
+	for _, readings := range sn.readingsBySensorID {
+		st.Readings += len(readings)
+	}
+	return st, nil
+}
+
+// History implementation for plugin
+func (p *Plugin) History(ctx context.Context, key hwsensorsservice.ReadingKey, since time.Time, maxPoints int) ([]hwsensorsservice.Sample, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.Service.History(hwinfo.ReadingKey{SensorID: key.SensorID, ReadingID: key.ReadingID}, since, maxPoints)
+}
+
+// FindReadings implementation for plugin, all readings are
+// from the same snapshot
+func (p *Plugin) FindReadings(ctx context.Context, q hwsensorsservice.Query) ([]hwsensorsservice.Found, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sn, err := p.Service.acquire()
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	defer sn.shmem.Release()
+	if err := sn.index(); err != nil {
+		return nil, serviceError(err)
+	}
+	var sensors []hwsensorsservice.Sensor
+	for s := range sn.shmem.IterSensors() {
+		sensors = append(sensors, &sensor{s.Clone()})
+	}
+	return hwsensorsservice.MatchReadings(q, sensors, func(id string) ([]hwsensorsservice.Reading, error) {
+		var readings []hwsensorsservice.Reading
+		for _, r := range sn.readingsBySensorID[id] {
+			readings = append(readings, &reading{r})
+		}
+		return readings, nil
+	})
+}
+
+// layoutError HWiNFO is running, its shared memory cannot be decoded
+func layoutError(err error) bool {
+	return errors.Is(err, hwinfo.ErrTruncated) || errors.Is(err, hwinfo.ErrLayout) || errors.Is(err, hwinfo.ErrUnsupportedVersion)
+}
+
+// serviceError wraps err of the shared memory with the
+// hwsensorsservice error callers can act on
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, hwsensorsservice.ErrSensorNotFound):
+		return err
+	case layoutError(err):
+		return fmt.Errorf("%w: %v", hwsensorsservice.ErrLayoutMismatch, err)
+	default:
+		// inactive, not opened or nothing received yet
+		return fmt.Errorf("%w: %v", hwsensorsservice.ErrUnavailable, err)
+	}
+}
+
 type sensor struct {
 	hwinfo.Sensor
 }
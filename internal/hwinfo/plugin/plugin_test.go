@@ -0,0 +1,65 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+func TestSubscribe(t *testing.T) {
+	s := NewService(nil)
+	feed(t, s, []uint64{1})
+	p := &Plugin{Service: s}
+	sensors, err := p.Sensors(context.Background())
+	if err != nil || len(sensors) != 1 {
+		t.Fatalf("Sensors = %v, %v", sensors, err)
+	}
+	key := hwsensorsservice.ReadingKey{SensorID: sensors[0].ID(), ReadingID: 1}
+	gone := hwsensorsservice.ReadingKey{SensorID: sensors[0].ID(), ReadingID: 9}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// served natively, not polled by hwsensorsservice.PollSubscribe
+	ch, err := hwsensorsservice.Subscribe(ctx, p, []hwsensorsservice.ReadingKey{key, gone}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() hwsensorsservice.Update {
+		t.Helper()
+		select {
+		case u := <-ch:
+			return u
+		case <-time.After(2 * time.Second):
+			t.Fatal("no update")
+		}
+		return hwsensorsservice.Update{}
+	}
+
+	// the current snapshot right away
+	u := next()
+	if u.PollTime != 1 || u.Readings[key].Value() != 1 || len(u.Missing) != 1 || u.Missing[0] != gone {
+		t.Fatalf("first update = %+v", u)
+	}
+	select {
+	case u := <-ch:
+		t.Fatalf("update %+v without a new snapshot", u)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// pushed as soon as the next snapshot is received
+	for _, pt := range []uint64{2, 3} {
+		feed(t, s, []uint64{pt})
+		if u := next(); u.PollTime != pt || u.Readings[key].Value() != float64(pt) {
+			t.Errorf("update = %+v, want poll %d", u, pt)
+		}
+	}
+
+	cancel()
+	for range ch {
+	}
+	if n := len(s.polls); n != 0 {
+		t.Errorf("%d poll subscriptions left after cancel", n)
+	}
+}
@@ -1,128 +1,330 @@
 package plugin
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log"
 	"sync"
+	"time"
 
 	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
 )
 
 // Service wraps hwinfo shared mem streaming
 // and provides convenient methods for data access
 type Service struct {
-	streamch           <-chan hwinfo.Result
-	mu                 sync.RWMutex
+	streamch <-chan hwinfo.Result
+	mu       sync.RWMutex
+	cur      *snapshot
+	// err is the last receive error, reported until the next good snapshot
+	err error
+	// lastErr is the last receive error, kept for diagnostics
+	lastErr error
+	// lastRead time the current snapshot was received
+	lastRead time.Time
+
+	subsMu sync.Mutex
+	subs   map[chan []hwinfo.Event]struct{}
+	polls  map[chan struct{}]struct{}
+
+	hist *history
+}
+
+// snapshot a received shared memory snapshot and its indexes,
+// built on first use and never modified afterwards
+type snapshot struct {
+	shmem *hwinfo.SharedMemory
+
+	once               sync.Once
 	sensorIDByIdx      []string
 	readingsBySensorID map[string][]hwinfo.Reading
-	shmem              *hwinfo.SharedMemory
-	readingsBuilt      bool
+	err                error
 }
 
-// Start starts the service providing updating hardware info
-func StartService() *Service {
+// index builds the indexes, the caller must hold shmem. Readings are
+// cloned so they stay valid once the snapshot is released.
+func (sn *snapshot) index() error {
+	sn.once.Do(func() {
+		for sens := range sn.shmem.IterSensors() {
+			sn.sensorIDByIdx = append(sn.sensorIDByIdx, sens.ID())
+		}
+		sn.readingsBySensorID = make(map[string][]hwinfo.Reading)
+		for r := range sn.shmem.IterReadings() {
+			sidx := int(r.SensorIndex())
+			if sidx >= len(sn.sensorIDByIdx) {
+				// keep ranging so the iterator does not leak
+				if sn.err == nil {
+					sn.err = fmt.Errorf("%w: sensor at index %d out of range", hwinfo.ErrLayout, sidx)
+				}
+				continue
+			}
+			sid := sn.sensorIDByIdx[sidx]
+			sn.readingsBySensorID[sid] = append(sn.readingsBySensorID[sid], r.Clone())
+		}
+	})
+	return sn.err
+}
+
+// eventBufferLen snapshots of events buffered per subscriber
+// before further events are dropped
+const eventBufferLen = 16
+
+// StartService starts the service providing updating hardware info
+// from HWiNFO shared memory until ctx is done
+func StartService(ctx context.Context, opts hwinfo.StreamOptions) *Service {
+	return NewService(hwinfo.StreamSharedMem(ctx, opts))
+}
+
+// NewService creates a service fed by results from streamch,
+// e.g. a capture replay instead of live shared memory
+func NewService(streamch <-chan hwinfo.Result) *Service {
 	return &Service{
-		streamch: hwinfo.StreamSharedMem(),
+		streamch: streamch,
+		hist:     newHistory(DefaultHistoryOptions),
 	}
 }
 
+// KeepHistory configures the reading history, call it before
+// receiving. A Depth of 0 keeps none.
+func (s *Service) KeepHistory(opts HistoryOptions) {
+	s.hist = newHistory(opts)
+}
+
+// History samples of a reading at or after since, oldest first,
+// at most the newest maxPoints unless maxPoints is 0
+func (s *Service) History(key hwinfo.ReadingKey, since time.Time, maxPoints int) ([]hwsensorsservice.Sample, error) {
+	return s.hist.samples(key, since, maxPoints)
+}
+
+// HistoryUsage memory held by the reading history
+func (s *Service) HistoryUsage() HistoryUsage {
+	return s.hist.usage()
+}
+
 func (s *Service) recvShmem(shmem *hwinfo.SharedMemory) error {
 	if shmem == nil {
 		return fmt.Errorf("shmem nil")
 	}
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	old := s.cur
+	s.cur = &snapshot{shmem: shmem}
+	s.lastRead = time.Now()
+	s.err = nil
+	s.mu.Unlock()
+
+	// only Recv replaces the snapshot, it and the old one stay valid
+	// until released below, readers are not held up meanwhile
+	s.hist.record(time.Now(), shmem)
+	s.signalPolls()
+	if old != nil {
+		s.publish(old.shmem, shmem)
+		// callers still holding the old snapshot retained it
+		old.shmem.Release()
+	} else {
+		s.publish(nil, shmem)
+	}
+	return nil
+}
 
-	s.shmem = shmem
+// Subscribe delivers the changes between consecutive snapshots,
+// the first delivery reports everything as added. Call the returned
+// func to unsubscribe, which closes the channel.
+func (s *Service) Subscribe() (<-chan []hwinfo.Event, func()) {
+	ch := make(chan []hwinfo.Event, eventBufferLen)
+	s.subsMu.Lock()
+	if s.subs == nil {
+		s.subs = make(map[chan []hwinfo.Event]struct{})
+	}
+	s.subs[ch] = struct{}{}
+	s.subsMu.Unlock()
 
-	s.sensorIDByIdx = s.sensorIDByIdx[:0]
-	for k, v := range s.readingsBySensorID {
-		s.readingsBySensorID[k] = v[:0]
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.subsMu.Lock()
+			delete(s.subs, ch)
+			s.subsMu.Unlock()
+			close(ch)
+		})
 	}
-	s.readingsBuilt = false
+}
 
-	return nil
+func (s *Service) publish(old, new *hwinfo.SharedMemory) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+
+	if len(s.subs) == 0 {
+		return
+	}
+	events := hwinfo.Diff(old, new)
+	if len(events) == 0 {
+		return
+	}
+	for ch := range s.subs {
+		select {
+		case ch <- events:
+		default:
+			log.Printf("subscriber not keeping up, dropped %d events\n", len(events))
+		}
+	}
 }
 
-// Recv receives new hardware sensor updates
+// Polls signals every snapshot received, once it is current and
+// recorded in the history. Signals not yet taken are merged into one.
+// Call the returned func to stop, which closes the channel.
+func (s *Service) Polls() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	s.subsMu.Lock()
+	if s.polls == nil {
+		s.polls = make(map[chan struct{}]struct{})
+	}
+	s.polls[ch] = struct{}{}
+	s.subsMu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.subsMu.Lock()
+			delete(s.polls, ch)
+			s.subsMu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (s *Service) signalPolls() {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+
+	for ch := range s.polls {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *Service) recvErr(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err, s.lastErr = err, err
+	return err
+}
+
+// Recv receives new hardware sensor updates,
+// returns io.EOF once the stream is closed.
+// A failed read, e.g. hwinfo.ErrInactive, is returned and
+// reported by Shmem until the next successful read.
 func (s *Service) Recv() error {
 	select {
-	case r := <-s.streamch:
+	case r, ok := <-s.streamch:
+		if !ok {
+			return io.EOF
+		}
 		if r.Err != nil {
-			return r.Err
+			return s.recvErr(r.Err)
 		}
 		return s.recvShmem(r.Shmem)
 	}
 }
 
-// Shmem provides access to underlying hwinfo shared memory
-func (s *Service) Shmem() (*hwinfo.SharedMemory, error) {
+// acquire the current snapshot, retained until the caller releases it
+func (s *Service) acquire() (*snapshot, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if s.shmem != nil {
-		return s.shmem, nil
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.cur == nil {
+		return nil, fmt.Errorf("shmem nil")
 	}
-	return nil, fmt.Errorf("shmem nil")
+	s.cur.shmem.Retain()
+	return s.cur, nil
 }
 
-// SensorIDByIdx returns ordered slice of sensor IDs
-func (s *Service) SensorIDByIdx() ([]string, error) {
-	s.mu.RLock()
-	if len(s.sensorIDByIdx) > 0 {
-		defer s.mu.RUnlock()
-		return s.sensorIDByIdx, nil
+// Shmem provides access to underlying hwinfo shared memory,
+// the snapshot is retained and must be released once done with
+func (s *Service) Shmem() (*hwinfo.SharedMemory, error) {
+	sn, err := s.acquire()
+	if err != nil {
+		return nil, err
 	}
-	s.mu.RUnlock()
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	return sn.shmem, nil
+}
 
-	for sens := range s.shmem.IterSensors() {
-		s.sensorIDByIdx = append(s.sensorIDByIdx, sens.ID())
+// Version shared memory version and revision of the current snapshot
+func (s *Service) Version() (version int, revision int, err error) {
+	shmem, err := s.Shmem()
+	if err != nil {
+		return 0, 0, err
 	}
-
-	return s.sensorIDByIdx, nil
+	defer shmem.Release()
+	return shmem.Version(), shmem.Revision(), nil
 }
 
-// ReadingsBySensorID returns slice of hwinfoReading for a given sensor ID
-func (s *Service) ReadingsBySensorID(id string) ([]hwinfo.Reading, error) {
-	s.mu.RLock()
-	if s.readingsBySensorID != nil && s.readingsBuilt {
-		defer s.mu.RUnlock()
-		readings, ok := s.readingsBySensorID[id]
-		if !ok {
-			return nil, fmt.Errorf("readings for sensor id %s do not exist", id)
-		}
-		return readings, nil
+// SensorIDByIdx returns ordered slice of sensor IDs,
+// shared between callers and must not be modified
+func (s *Service) SensorIDByIdx() ([]string, error) {
+	sn, err := s.acquire()
+	if err != nil {
+		return nil, err
 	}
-	s.mu.RUnlock()
+	defer sn.shmem.Release()
 
-	sids, err := s.SensorIDByIdx()
-	if err != nil {
+	if err := sn.index(); err != nil {
 		return nil, err
 	}
+	return sn.sensorIDByIdx, nil
+}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// ReadingsBySensorIDs returns the readings of every sensor in ids from
+// the same snapshot, sensors that do not exist are left out
+func (s *Service) ReadingsBySensorIDs(ids []string) (map[string][]hwinfo.Reading, error) {
+	_, res, err := s.readingsBySensorIDs(ids)
+	return res, err
+}
 
-	if s.readingsBySensorID == nil {
-		s.readingsBySensorID = make(map[string][]hwinfo.Reading)
+// readingsBySensorIDs ReadingsBySensorIDs along with the poll time of
+// the snapshot the readings are from
+func (s *Service) readingsBySensorIDs(ids []string) (uint64, map[string][]hwinfo.Reading, error) {
+	sn, err := s.acquire()
+	if err != nil {
+		return 0, nil, err
 	}
+	defer sn.shmem.Release()
 
-	for r := range s.shmem.IterReadings() {
-		sidx := int(r.SensorIndex())
-		if sidx < len(sids) {
-			sid := sids[sidx]
-			s.readingsBySensorID[sid] = append(s.readingsBySensorID[sid], r)
-		} else {
-			return nil, fmt.Errorf("sensor at index %d out of range ", sidx)
+	if err := sn.index(); err != nil {
+		return 0, nil, err
+	}
+	res := make(map[string][]hwinfo.Reading, len(ids))
+	for _, id := range ids {
+		if readings, ok := sn.readingsBySensorID[id]; ok {
+			res[id] = readings
 		}
 	}
-	s.readingsBuilt = true
+	return sn.shmem.PollTime(), res, nil
+}
+
+// ReadingsBySensorID returns slice of hwinfoReading for a given sensor ID.
+// The readings own their bytes and are safe to keep across snapshots,
+// the slice is shared between callers and must not be modified.
+func (s *Service) ReadingsBySensorID(id string) ([]hwinfo.Reading, error) {
+	sn, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer sn.shmem.Release()
 
-	readings, ok := s.readingsBySensorID[id]
+	if err := sn.index(); err != nil {
+		return nil, err
+	}
+	readings, ok := sn.readingsBySensorID[id]
 	if !ok {
-		return nil, fmt.Errorf("readings for sensor id %s do not exist", id)
+		return nil, fmt.Errorf("%w: readings for sensor id %s do not exist", hwsensorsservice.ErrSensorNotFound, id)
 	}
 	return readings, nil
 }
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package hwinfo
+
+import "errors"
+
+// ReadSharedMem is only supported on Windows, where HWiNFO runs
+func ReadSharedMem() (*SharedMemory, error) {
+	return nil, errors.New("HWiNFO shared memory is only available on Windows")
+}
@@ -0,0 +1,11 @@
+package hwinfo
+
+import (
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/shmem"
+)
+
+// ReadSharedMem reads data from HWiNFO shared memory into a
+// pooled snapshot, Release it once done
+func ReadSharedMem() (*SharedMemory, error) {
+	return ReadPooled(shmem.ReadBytes)
+}
@@ -1,12 +1,8 @@
 package hwinfo
 
-/*
-#include <windows.h>
-#include "hwisenssm2.h"
-*/
-import "C"
 import (
-	"unsafe"
+	"encoding/binary"
+	"math"
 
 	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/util"
 )
@@ -41,84 +37,76 @@ func (t ReadingType) String() string {
 
 // Reading element (e.g. usage, power, mhz...)
 type Reading struct {
-	cr C.PHWiNFO_SENSORS_READING_ELEMENT
+	data []byte
 }
 
 // NewReading contructs a Reading
 func NewReading(data []byte) Reading {
 	return Reading{
-		cr: C.PHWiNFO_SENSORS_READING_ELEMENT(unsafe.Pointer(&data[0])),
+		data: data,
 	}
 }
 
+func (r *Reading) dword(off int) uint32 {
+	return binary.LittleEndian.Uint32(r.data[off:])
+}
+
+func (r *Reading) double(off int) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(r.data[off:]))
+}
+
 // ID unique ID of the reading within a particular sensor
 func (r *Reading) ID() int32 {
-	return int32(r.cr.dwReadingID)
+	return int32(r.dword(offReadingID))
 }
 
 // Type of sensor reading
 func (r *Reading) Type() ReadingType {
-	return ReadingType(r.cr.tReading)
+	return ReadingType(r.dword(offReadingType))
 }
 
 // SensorIndex this is the index of sensor in the Sensors[] array to
 // which this reading belongs to
 func (r *Reading) SensorIndex() uint64 {
-	return uint64(r.cr.dwSensorIndex)
+	return uint64(r.dword(offReadingSensorIndex))
 }
 
 // ReadingID a unique ID of the reading within a particular sensor
 func (r *Reading) ReadingID() uint64 {
-	return uint64(r.cr.dwReadingID)
+	return uint64(r.dword(offReadingID))
 }
 
 // LabelOrig original label (e.g. "Chassis2 Fan")
 func (r *Reading) LabelOrig() string {
-	return util.DecodeCharPtr(unsafe.Pointer(&r.cr.szLabelOrig), C.HWiNFO_SENSORS_STRING_LEN2)
+	return util.DecodeCString(r.data[offReadingLabelOrig : offReadingLabelOrig+stringLen])
 }
 
 // LabelUser label displayed, which might have been renamed by user
 func (r *Reading) LabelUser() string {
-	return util.DecodeCharPtr(unsafe.Pointer(&r.cr.szLabelUser), C.HWiNFO_SENSORS_STRING_LEN2)
+	return util.DecodeCString(r.data[offReadingLabelUser : offReadingLabelUser+stringLen])
 }
 
 // Unit e.g. "RPM"
 func (r *Reading) Unit() string {
-	return util.DecodeCharPtr(unsafe.Pointer(&r.cr.szUnit), C.HWiNFO_UNIT_STRING_LEN)
-}
-
-func (r *Reading) valuePtr() unsafe.Pointer {
-	return unsafe.Pointer(uintptr(unsafe.Pointer(&r.cr.szUnit)) + C.HWiNFO_UNIT_STRING_LEN)
+	return util.DecodeCString(r.data[offReadingUnit : offReadingUnit+unitStringLen])
 }
 
 // Value current value
 func (r *Reading) Value() float64 {
-	return float64(*(*C.double)(r.valuePtr()))
-}
-
-func (r *Reading) valueMinPtr() unsafe.Pointer {
-	return unsafe.Pointer(uintptr(r.valuePtr()) + C.sizeof_double)
+	return r.double(offReadingValue)
 }
 
 // ValueMin current value
 func (r *Reading) ValueMin() float64 {
-	return float64(*(*C.double)(r.valueMinPtr()))
-}
-
-func (r *Reading) valueMaxPtr() unsafe.Pointer {
-	return unsafe.Pointer(uintptr(r.valueMinPtr()) + C.sizeof_double)
+	return r.double(offReadingValueMin)
 }
 
 // ValueMax current value
 func (r *Reading) ValueMax() float64 {
-	return float64(*(*C.double)(r.valueMaxPtr()))
-}
-
-func (r *Reading) valueAvgPtr() unsafe.Pointer {
-	return unsafe.Pointer(uintptr(r.valueMaxPtr()) + C.sizeof_double)
+	return r.double(offReadingValueMax)
 }
 
 // ValueAvg current value
 func (r *Reading) ValueAvg() float64 {
-	return float64(*(*C.double)(r.valueAvgPtr()))
+	return r.double(offReadingValueAvg)
 }
@@ -1,38 +1,32 @@
 package hwinfo
 
-/*
-#include <windows.h>
-#include "hwisenssm2.h"
-*/
-import "C"
-
 import (
+	"encoding/binary"
 	"strconv"
-	"unsafe"
 
 	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/util"
 )
 
 // Sensor element (e.g. motherboard, cpu, gpu...)
 type Sensor struct {
-	cs C.PHWiNFO_SENSORS_SENSOR_ELEMENT
+	data []byte
 }
 
 // NewSensor constructs a Sensor
 func NewSensor(data []byte) Sensor {
 	return Sensor{
-		cs: C.PHWiNFO_SENSORS_SENSOR_ELEMENT(unsafe.Pointer(&data[0])),
+		data: data,
 	}
 }
 
 // SensorID a unique Sensor ID
 func (s *Sensor) SensorID() uint64 {
-	return uint64(s.cs.dwSensorID)
+	return uint64(binary.LittleEndian.Uint32(s.data[offSensorID:]))
 }
 
 // SensorInst the instance of the sensor (together with SensorID forms a unique ID)
 func (s *Sensor) SensorInst() uint64 {
-	return uint64(s.cs.dwSensorInst)
+	return uint64(binary.LittleEndian.Uint32(s.data[offSensorInst:]))
 }
 
 // ID a unique ID combining SensorID and SensorInst
@@ -43,10 +37,10 @@ func (s *Sensor) ID() string {
 
 // NameOrig original name of sensor
 func (s *Sensor) NameOrig() string {
-	return util.DecodeCharPtr(unsafe.Pointer(&s.cs.szSensorNameOrig), C.HWiNFO_SENSORS_STRING_LEN2)
+	return util.DecodeCString(s.data[offSensorNameOrig : offSensorNameOrig+stringLen])
 }
 
 // NameUser sensor name displayed, which might have been renamed by user
 func (s *Sensor) NameUser() string {
-	return util.DecodeCharPtr(unsafe.Pointer(&s.cs.szSensorNameUser), C.HWiNFO_SENSORS_STRING_LEN2)
+	return util.DecodeCString(s.data[offSensorNameUser : offSensorNameUser+stringLen])
 }
@@ -1,3 +1,5 @@
+//go:build windows
+
 package shmem
 
 /*
@@ -17,9 +19,7 @@ import (
 	"golang.org/x/sys/windows"
 )
 
-var buf = make([]byte, 200000)
-
-func copyBytes(addr uintptr) []byte {
+func copyBytes(dst []byte, addr uintptr) []byte {
 	headerLen := C.sizeof_HWiNFO_SENSORS_SHARED_MEM2
 
 	var d []byte
@@ -31,19 +31,21 @@ func copyBytes(addr uintptr) []byte {
 	cheader := C.PHWiNFO_SENSORS_SHARED_MEM2(unsafe.Pointer(&d[0]))
 	fullLen := int(cheader.dwOffsetOfReadingSection + (cheader.dwSizeOfReadingElement * cheader.dwNumReadingElements))
 
-	if fullLen > cap(buf) {
-		buf = append(buf, make([]byte, fullLen-cap(buf))...)
+	if fullLen > cap(dst) {
+		dst = make([]byte, fullLen)
 	}
+	dst = dst[:fullLen]
 
 	dh.Len, dh.Cap = fullLen, fullLen
 
-	copy(buf, d)
+	copy(dst, d)
 
-	return buf[:fullLen]
+	return dst
 }
 
-// ReadBytes copies bytes from global shared memory
-func ReadBytes() ([]byte, error) {
+// ReadBytes copies bytes from global shared memory into dst,
+// dst is grown when too small and the filled slice is returned
+func ReadBytes(dst []byte) ([]byte, error) {
 	err := mutex.Lock()
 	defer mutex.Unlock()
 	if err != nil {
@@ -61,7 +63,7 @@ func ReadBytes() ([]byte, error) {
 	defer unmapViewOfFile(addr)
 	defer windows.CloseHandle(windows.Handle(unsafe.Pointer(hnd)))
 
-	return copyBytes(addr), nil
+	return copyBytes(dst, addr), nil
 }
 
 func openFileMapping() (C.HANDLE, error) {
@@ -0,0 +1,75 @@
+package hwinfo
+
+import "sync"
+
+// snapshot buffers are recycled once every holder released the
+// snapshot, steady state polling reads into the same few buffers
+var bufPool = sync.Pool{
+	New: func() any { return new([]byte) },
+}
+
+// BufferFunc copies a shared memory snapshot into dst, growing it when
+// too small, and returns the filled slice, e.g. shmem.ReadBytes
+type BufferFunc func(dst []byte) ([]byte, error)
+
+// ReadPooled reads a snapshot with read into a pooled buffer.
+// The snapshot is returned retained once, call Release when done
+// with it so the buffer can be reused by a later read.
+func ReadPooled(read BufferFunc) (*SharedMemory, error) {
+	buf := bufPool.Get().(*[]byte)
+	data, err := read((*buf)[:0])
+	if err != nil {
+		bufPool.Put(buf)
+		return nil, err
+	}
+	*buf = data
+
+	s, err := NewSharedMemory(data)
+	if err != nil {
+		bufPool.Put(buf)
+		return nil, err
+	}
+	s.buf = buf
+	s.refs.Store(1)
+	return s, nil
+}
+
+// Retain marks s as in use by one more holder, each Retain must be
+// paired with a Release. Returns s for convenience.
+func (s *SharedMemory) Retain() *SharedMemory {
+	if s.buf != nil {
+		if s.refs.Add(1) <= 1 {
+			panic("hwinfo: Retain of released SharedMemory")
+		}
+	}
+	return s
+}
+
+// Release gives up a hold on s. Once the last holder released a pooled
+// snapshot its bytes are reused, s and the Sensors and Readings taken
+// from it must not be used anymore. Snapshots not read with ReadPooled
+// own plain memory and Release does nothing.
+func (s *SharedMemory) Release() {
+	if s == nil || s.buf == nil {
+		return
+	}
+	switch n := s.refs.Add(-1); {
+	case n == 0:
+		s.data = nil
+		bufPool.Put(s.buf)
+	case n < 0:
+		panic("hwinfo: SharedMemory released more often than retained")
+	}
+}
+
+// Clone a copy of the sensor that owns its bytes,
+// safe to keep after the snapshot was released
+func (s Sensor) Clone() Sensor {
+	return NewSensor(append([]byte(nil), s.data...))
+}
+
+// Clone a copy of the reading that owns its bytes,
+// safe to keep after the snapshot was released
+func (r Reading) Clone() Reading {
+	return NewReading(append([]byte(nil), r.data...))
+}
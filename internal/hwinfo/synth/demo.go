@@ -0,0 +1,39 @@
+package synth
+
+import (
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
+)
+
+// Demo sensors resembling a desktop PC, for demos and running the
+// plugin without HWiNFO
+func Demo() []Sensor {
+	return []Sensor{
+		{
+			ID: 0xf0000300, Inst: 0, Name: "CPU [#0]: Synthetic CPU", NameUser: "CPU",
+			Readings: []Reading{
+				{ID: 0x1000000, Type: hwinfo.ReadingTypeTemp, Label: "CPU (Tctl/Tdie)", Unit: "°C", Value: Sine{Min: 38, Max: 82, Period: time.Minute}},
+				{ID: 0x2000000, Type: hwinfo.ReadingTypeVolt, Label: "CPU Core Voltage", Unit: "V", Value: NewRandomWalk(1, 1.2, 0.9, 1.45, 0.02)},
+				{ID: 0x6000000, Type: hwinfo.ReadingTypeClock, Label: "Core 0 Clock", Unit: "MHz", Value: NewRandomWalk(2, 3600, 2200, 4900, 150)},
+				{ID: 0x7000000, Type: hwinfo.ReadingTypeUsage, Label: "Total CPU Usage", Unit: "%", Value: NewRandomWalk(3, 20, 0, 100, 8)},
+				{ID: 0x5000000, Type: hwinfo.ReadingTypePower, Label: "CPU Package Power", Unit: "W", Value: Step{Values: []float64{35, 65, 120, 65}, Every: 15 * time.Second}},
+			},
+		},
+		{
+			ID: 0xe0002000, Inst: 0, Name: "GPU [#0]: Synthetic GPU",
+			Readings: []Reading{
+				{ID: 0x1000000, Type: hwinfo.ReadingTypeTemp, Label: "GPU Temperature", Unit: "°C", Value: Sine{Min: 35, Max: 75, Period: 3 * time.Minute}},
+				{ID: 0x3000000, Type: hwinfo.ReadingTypeFan, Label: "GPU Fan", Unit: "RPM", Value: Step{Values: []float64{0, 1100, 1800, 1100}, Every: 20 * time.Second}},
+				{ID: 0x7000000, Type: hwinfo.ReadingTypeUsage, Label: "GPU Core Load", Unit: "%", Value: NewRandomWalk(4, 10, 0, 100, 12)},
+			},
+		},
+		{
+			ID: 0xf0000100, Inst: 0, Name: "System: Synthetic Board",
+			Readings: []Reading{
+				{ID: 0x7000001, Type: hwinfo.ReadingTypeUsage, Label: "Physical Memory Used", Unit: "MB", Value: NewRandomWalk(5, 8192, 4096, 30000, 256)},
+				{ID: 0x8000000, Type: hwinfo.ReadingTypeOther, Label: "Chassis Intrusion", Unit: "Yes/No", Value: Constant(0)},
+			},
+		},
+	}
+}
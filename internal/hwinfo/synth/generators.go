@@ -0,0 +1,83 @@
+package synth
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Generator produces the value of a reading at time t since the start
+// of the source. Value is called once per snapshot with increasing t.
+type Generator interface {
+	Value(t time.Duration) float64
+}
+
+// Constant always the same value
+type Constant float64
+
+// Value implements Generator
+func (c Constant) Value(time.Duration) float64 {
+	return float64(c)
+}
+
+// Sine oscillates between Min and Max once per Period
+type Sine struct {
+	Min    float64
+	Max    float64
+	Period time.Duration
+}
+
+// Value implements Generator
+func (s Sine) Value(t time.Duration) float64 {
+	if s.Period <= 0 {
+		return s.Min
+	}
+	phase := 2 * math.Pi * float64(t) / float64(s.Period)
+	return s.Min + (s.Max-s.Min)*(1+math.Sin(phase))/2
+}
+
+// Step cycles through Values, holding each for Every
+type Step struct {
+	Values []float64
+	Every  time.Duration
+}
+
+// Value implements Generator
+func (s Step) Value(t time.Duration) float64 {
+	if len(s.Values) == 0 {
+		return 0
+	}
+	if s.Every <= 0 {
+		return s.Values[0]
+	}
+	return s.Values[int(t/s.Every)%len(s.Values)]
+}
+
+// RandomWalk moves by up to MaxStep in either direction on every call,
+// staying within Min and Max
+type RandomWalk struct {
+	Min     float64
+	Max     float64
+	MaxStep float64
+
+	rnd *rand.Rand
+	v   float64
+}
+
+// NewRandomWalk starts a walk at start, seed makes the walk repeatable
+func NewRandomWalk(seed int64, start, min, max, maxStep float64) *RandomWalk {
+	return &RandomWalk{
+		Min:     min,
+		Max:     max,
+		MaxStep: maxStep,
+		rnd:     rand.New(rand.NewSource(seed)),
+		v:       start,
+	}
+}
+
+// Value implements Generator
+func (w *RandomWalk) Value(time.Duration) float64 {
+	w.v += (w.rnd.Float64()*2 - 1) * w.MaxStep
+	w.v = math.Max(w.Min, math.Min(w.Max, w.v))
+	return w.v
+}
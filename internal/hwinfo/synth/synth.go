@@ -0,0 +1,127 @@
+// Package synth builds valid HWiNFO shared memory snapshots from a
+// description of sensors and readings, a stand-in for HWiNFO on
+// machines without it
+package synth
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
+)
+
+// Reading describes a synthetic reading
+type Reading struct {
+	ID        uint32
+	Type      hwinfo.ReadingType
+	Label     string
+	LabelUser string
+	Unit      string
+	Value     Generator
+}
+
+// Sensor describes a synthetic sensor and its readings
+type Sensor struct {
+	ID       uint32
+	Inst     uint32
+	Name     string
+	NameUser string
+	Readings []Reading
+}
+
+type stats struct {
+	min, max, sum float64
+	n             int
+}
+
+// Source produces snapshots of its sensors, tracking min, max and
+// average of every reading the way HWiNFO does
+type Source struct {
+	mu       sync.Mutex
+	sensors  []Sensor
+	start    time.Time
+	now      func() time.Time
+	stats    []stats
+	elements []hwinfo.SensorElement
+	readings []hwinfo.ReadingElement
+}
+
+// NewSource creates a Source, time starts now
+func NewSource(sensors []Sensor) *Source {
+	s := &Source{sensors: sensors, now: time.Now}
+	s.start = s.now()
+	for i, sens := range sensors {
+		s.elements = append(s.elements, hwinfo.SensorElement{
+			SensorID:   sens.ID,
+			SensorInst: sens.Inst,
+			NameOrig:   sens.Name,
+			NameUser:   userOr(sens.NameUser, sens.Name),
+		})
+		for _, r := range sens.Readings {
+			s.readings = append(s.readings, hwinfo.ReadingElement{
+				Type:        r.Type,
+				SensorIndex: uint32(i),
+				ReadingID:   r.ID,
+				LabelOrig:   r.Label,
+				LabelUser:   userOr(r.LabelUser, r.Label),
+				Unit:        r.Unit,
+			})
+		}
+	}
+	s.stats = make([]stats, len(s.readings))
+	return s
+}
+
+// HWiNFO reports the original name when not renamed
+func userOr(user, orig string) string {
+	if user != "" {
+		return user
+	}
+	return orig
+}
+
+// Read advances every generator and returns a new snapshot,
+// it has the signature of hwinfo.ReadFunc
+func (s *Source) Read() (*hwinfo.SharedMemory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	t := now.Sub(s.start)
+	i := 0
+	for _, sens := range s.sensors {
+		for _, r := range sens.Readings {
+			v := 0.
+			if r.Value != nil {
+				v = r.Value.Value(t)
+			}
+			st := &s.stats[i]
+			if st.n == 0 {
+				st.min, st.max = v, v
+			}
+			st.min = math.Min(st.min, v)
+			st.max = math.Max(st.max, v)
+			st.sum += v
+			st.n++
+
+			e := &s.readings[i]
+			e.Value = v
+			e.ValueMin = st.min
+			e.ValueMax = st.max
+			e.ValueAvg = st.sum / float64(st.n)
+			i++
+		}
+	}
+
+	// every snapshot owns its bytes, readers may hold on to them
+	data := hwinfo.Encode(nil, uint64(now.Unix()), s.elements, s.readings)
+	return hwinfo.NewSharedMemory(data)
+}
+
+// Stream delivers snapshots of src in the same manner as
+// hwinfo.StreamSharedMem
+func Stream(ctx context.Context, opts hwinfo.StreamOptions, src *Source) <-chan hwinfo.Result {
+	return hwinfo.Stream(ctx, opts, src.Read)
+}
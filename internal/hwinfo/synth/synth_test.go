@@ -0,0 +1,60 @@
+package synth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo"
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/plugin"
+	"github.com/shayne/hwinfo-streamdeck/internal/hwinfo/synth"
+)
+
+// alternate returns 40, 60, 40, ...
+type alternate struct{ n int }
+
+func (a *alternate) Value(time.Duration) float64 {
+	a.n++
+	return float64(20 + 20*(a.n%2+1))
+}
+
+func TestSourceThroughPlugin(t *testing.T) {
+	src := synth.NewSource([]synth.Sensor{{
+		ID: 1, Inst: 2, Name: "CPU [#0]", NameUser: "CPU",
+		Readings: []synth.Reading{
+			{ID: 10, Type: hwinfo.ReadingTypeTemp, Label: "Core", Unit: "°C", Value: &alternate{}},
+		},
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service := plugin.NewService(synth.Stream(ctx, hwinfo.StreamOptions{Interval: time.Millisecond}, src))
+	for i := 0; i < 3; i++ {
+		if err := service.Recv(); err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+	}
+
+	p := &plugin.Plugin{Service: service}
+	sensors, err := p.Sensors(context.Background())
+	if err != nil {
+		t.Fatalf("Sensors: %v", err)
+	}
+	if len(sensors) != 1 || sensors[0].ID() != "102" || sensors[0].Name() != "CPU [#0]" {
+		t.Fatalf("sensors = %+v", sensors)
+	}
+	readings, err := p.ReadingsForSensorID(context.Background(), "102")
+	if err != nil {
+		t.Fatalf("ReadingsForSensorID: %v", err)
+	}
+	if len(readings) != 1 {
+		t.Fatalf("got %d readings, want 1", len(readings))
+	}
+	r := readings[0]
+	if r.Label() != "Core" || r.Unit() != "°C" || r.Type() != "Temp" {
+		t.Errorf("reading = %q %q %q", r.Label(), r.Unit(), r.Type())
+	}
+	if r.ValueMin() != 40 || r.ValueMax() != 60 || r.ValueAvg() <= 40 || r.ValueAvg() >= 60 {
+		t.Errorf("min/max/avg = %v/%v/%v", r.ValueMin(), r.ValueMax(), r.ValueAvg())
+	}
+}
@@ -1,14 +1,11 @@
 package util
 
-import "C"
-
 import (
+	"bytes"
 	"errors"
 	"fmt"
-	"log"
-	"strings"
-	"unsafe"
 
+	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/charmap"
 )
 
@@ -39,17 +36,16 @@ func HandleLastError(code uint64) error {
 	}
 }
 
-func goStringFromPtr(ptr unsafe.Pointer, len int) string {
-	s := C.GoStringN((*C.char)(ptr), C.int(len))
-	return s[:strings.IndexByte(s, 0)]
-}
-
-// DecodeCharPtr decodes ISO8859_1 string to UTF-8
-func DecodeCharPtr(ptr unsafe.Pointer, len int) string {
-	s := goStringFromPtr(ptr, len)
-	ds, err := decodeISO8859_1(s)
+// DecodeCString decodes a NUL terminated ISO8859_1 string to UTF-8
+func DecodeCString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	ds, err := decodeISO8859_1(string(b))
 	if err != nil {
-		log.Fatalf("TODO: failed to decode: %v", err)
+		// every ISO8859_1 byte maps to a rune, should never happen,
+		// the raw bytes are more useful than failing
+		return string(b)
 	}
 	return ds
 }
@@ -59,3 +55,19 @@ var isodecoder = charmap.ISO8859_1.NewDecoder()
 func decodeISO8859_1(in string) (string, error) {
 	return isodecoder.String(in)
 }
+
+// EncodeCString encodes s as a NUL terminated ISO8859_1 string into dst,
+// truncating to fit and replacing unsupported runes
+func EncodeCString(dst []byte, s string) {
+	es, err := encoding.ReplaceUnsupported(charmap.ISO8859_1.NewEncoder()).String(s)
+	if err != nil {
+		es = s
+	}
+	if len(es) >= len(dst) {
+		es = es[:len(dst)-1]
+	}
+	n := copy(dst, es)
+	for i := n; i < len(dst); i++ {
+		dst[i] = 0
+	}
+}
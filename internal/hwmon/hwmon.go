@@ -0,0 +1,179 @@
+// Package hwmon reads Linux hardware monitoring sensors from sysfs,
+// see https://www.kernel.org/doc/html/latest/hwmon/sysfs-interface.html
+package hwmon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/backend"
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+// DefaultRoot sysfs directory of hwmon devices
+const DefaultRoot = "/sys/class/hwmon"
+
+// kind of sysfs channel, values are integers in the scaled unit
+type kind struct {
+	prefix string
+	typ    hwsensorsservice.ReadingType
+	unit   string
+	scale  float64
+}
+
+// ordered as readings are listed
+var kinds = []kind{
+	{"temp", hwsensorsservice.ReadingTypeTemp, "°C", 1e-3},   // millidegree
+	{"in", hwsensorsservice.ReadingTypeVolt, "V", 1e-3},      // millivolt
+	{"fan", hwsensorsservice.ReadingTypeFan, "RPM", 1},       // RPM
+	{"curr", hwsensorsservice.ReadingTypeCurrent, "A", 1e-3}, // milliampere
+	{"power", hwsensorsservice.ReadingTypePower, "W", 1e-6},  // microwatt
+}
+
+// matches e.g. temp1_input, power1_average is used for chips
+// only reporting average power
+var channelRe = regexp.MustCompile(`^(temp|in|fan|curr|power)(\d+)_(input|average)$`)
+
+// Source reads sensors from a sysfs hwmon class directory
+type Source struct {
+	root string
+}
+
+// NewSource creates a Source reading root, DefaultRoot when empty
+func NewSource(root string) *Source {
+	if root == "" {
+		root = DefaultRoot
+	}
+	return &Source{root: root}
+}
+
+// NewService creates a service for the hwmon devices under root
+func NewService(root string) *backend.Service {
+	return backend.NewService("hwmon", NewSource(root))
+}
+
+// Read implements backend.Source, one sensor per hwmon device
+func (s *Source) Read() ([]backend.Sensor, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, fmt.Errorf("hwmon: %w", err)
+	}
+	var sensors []backend.Sensor
+	for _, e := range entries {
+		sens, ok := readDevice(filepath.Join(s.root, e.Name()))
+		if ok {
+			sensors = append(sensors, sens)
+		}
+	}
+	return sensors, nil
+}
+
+type channel struct {
+	kind  int
+	index int
+	file  string
+}
+
+func readDevice(dir string) (backend.Sensor, bool) {
+	// older drivers keep their attributes on the parent device
+	attrDir := dir
+	name, err := readString(filepath.Join(dir, "name"))
+	if err != nil {
+		attrDir = filepath.Join(dir, "device")
+		if name, err = readString(filepath.Join(attrDir, "name")); err != nil {
+			return backend.Sensor{}, false
+		}
+	}
+
+	files, err := os.ReadDir(attrDir)
+	if err != nil {
+		return backend.Sensor{}, false
+	}
+	channels := make(map[[2]int]channel)
+	for _, f := range files {
+		m := channelRe.FindStringSubmatch(f.Name())
+		if m == nil {
+			continue
+		}
+		index, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue
+		}
+		k := kindIndex(m[1])
+		key := [2]int{k, index}
+		// prefer the instantaneous value over the average
+		if c, ok := channels[key]; ok && strings.HasSuffix(c.file, "_input") {
+			continue
+		}
+		channels[key] = channel{kind: k, index: index, file: f.Name()}
+	}
+
+	var sorted []channel
+	for _, c := range channels {
+		sorted = append(sorted, c)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].kind != sorted[j].kind {
+			return sorted[i].kind < sorted[j].kind
+		}
+		return sorted[i].index < sorted[j].index
+	})
+
+	sens := backend.Sensor{ID: deviceID(dir, name), Name: name}
+	for _, c := range sorted {
+		k := kinds[c.kind]
+		raw, err := readString(filepath.Join(attrDir, c.file))
+		if err != nil {
+			// e.g. ENODATA from a disconnected probe
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			continue
+		}
+		prefix := k.prefix + strconv.Itoa(c.index)
+		label, err := readString(filepath.Join(attrDir, prefix+"_label"))
+		if err != nil || label == "" {
+			label = prefix
+		}
+		sens.Readings = append(sens.Readings, backend.Reading{
+			ID:    int32(k.typ)<<24 | int32(c.index),
+			Type:  k.typ,
+			Label: label,
+			Unit:  k.unit,
+			Value: v * k.scale,
+		})
+	}
+	return sens, len(sens.Readings) > 0
+}
+
+func kindIndex(prefix string) int {
+	for i, k := range kinds {
+		if k.prefix == prefix {
+			return i
+		}
+	}
+	return -1
+}
+
+// deviceID hwmonN numbering changes between boots, the device the
+// hwmon belongs to, e.g. a PCI address, does not
+func deviceID(dir, name string) string {
+	if target, err := os.Readlink(filepath.Join(dir, "device")); err == nil {
+		return backend.SensorID("hwmon", name, filepath.Base(target))
+	}
+	return backend.SensorID("hwmon", name, filepath.Base(dir))
+}
+
+func readString(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
@@ -0,0 +1,90 @@
+package hwmon
+
+import (
+	"context"
+	"testing"
+
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+func TestRead(t *testing.T) {
+	sensors, err := NewSource("testdata/hwmon").Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	// acpitz has no channels and is skipped
+	if len(sensors) != 3 {
+		t.Fatalf("got %d sensors, want 3", len(sensors))
+	}
+
+	type want struct {
+		label string
+		typ   hwsensorsservice.ReadingType
+		unit  string
+		value float64
+	}
+	tests := []struct {
+		id       string
+		name     string
+		readings []want
+	}{
+		{"hwmon:k10temp/hwmon0", "k10temp", []want{
+			{"Tctl", hwsensorsservice.ReadingTypeTemp, "°C", 45.25},
+			{"Tccd1", hwsensorsservice.ReadingTypeTemp, "°C", 38},
+		}},
+		// named after the device linked, not the hwmon numbering
+		{"hwmon:nct6798/nct6775.656", "nct6798", []want{
+			{"Vcore", hwsensorsservice.ReadingTypeVolt, "V", 1.104},
+			{"fan1", hwsensorsservice.ReadingTypeFan, "RPM", 0},
+			{"fan2", hwsensorsservice.ReadingTypeFan, "RPM", 1250},
+			{"curr1", hwsensorsservice.ReadingTypeCurrent, "A", 1.5},
+		}},
+		{"hwmon:amdgpu/hwmon2", "amdgpu", []want{
+			{"PPT", hwsensorsservice.ReadingTypePower, "W", 35},
+		}},
+	}
+	for i, tt := range tests {
+		s := sensors[i]
+		if s.ID != tt.id || s.Name != tt.name {
+			t.Errorf("sensor %d = %s %s, want %s %s", i, s.ID, s.Name, tt.id, tt.name)
+		}
+		if len(s.Readings) != len(tt.readings) {
+			t.Errorf("%s: got %d readings, want %d", tt.name, len(s.Readings), len(tt.readings))
+			continue
+		}
+		for j, w := range tt.readings {
+			r := s.Readings[j]
+			if r.Label != w.label || r.Type != w.typ || r.Unit != w.unit || r.Value != w.value {
+				t.Errorf("%s reading %d = %s %v %s %v, want %s %v %s %v", tt.name, j, r.Label, r.Type, r.Unit, r.Value, w.label, w.typ, w.unit, w.value)
+			}
+		}
+	}
+}
+
+func TestService(t *testing.T) {
+	svc := NewService("testdata/hwmon")
+	if _, err := svc.Sensors(context.Background()); err == nil {
+		t.Error("expected error before first poll")
+	}
+	if st, _ := svc.Status(context.Background()); st.State != hwsensorsservice.StateUnknown {
+		t.Errorf("state before first poll = %v", st.State)
+	}
+	if err := svc.Poll(); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	st, err := svc.Status(context.Background())
+	if err != nil || st.Backend != "hwmon" || st.State != hwsensorsservice.StateActive || st.Sensors == 0 || st.Readings == 0 || st.LastRead.IsZero() {
+		t.Errorf("Status = %+v, %v", st, err)
+	}
+	readings, err := svc.ReadingsForSensorID(context.Background(), "hwmon:k10temp/hwmon0")
+	if err != nil {
+		t.Fatalf("ReadingsForSensorID: %v", err)
+	}
+	r := readings[0]
+	if r.ID() != int32(hwsensorsservice.ReadingTypeTemp)<<24|1 || r.Type() != "Temp" {
+		t.Errorf("reading ID %#x type %s", r.ID(), r.Type())
+	}
+	if r.ValueMin() != 45.25 || r.ValueMax() != 45.25 || r.ValueAvg() != 45.25 {
+		t.Errorf("stats %v %v %v, want 45.25", r.ValueMin(), r.ValueMax(), r.ValueAvg())
+	}
+}
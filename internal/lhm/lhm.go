@@ -0,0 +1,255 @@
+// Package lhm reads sensors from the web server of LibreHardwareMonitor
+// or OpenHardwareMonitor, which serves the sensor tree as data.json
+package lhm
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/backend"
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+// DefaultURL data.json of the web server with default settings
+const DefaultURL = "http://localhost:8085/data.json"
+
+// node of the data.json tree: the root, computer, hardware,
+// sensor type groups and sensors as leafs
+type node struct {
+	Text     string
+	Min      string
+	Value    string
+	Max      string
+	ImageURL string
+	// SensorId and Type are only set for sensors of LibreHardwareMonitor
+	SensorID string `json:"SensorId"`
+	Type     string
+	Children []node
+}
+
+// sensorType LibreHardwareMonitor SensorType or group name
+type sensorType struct {
+	typ hwsensorsservice.ReadingType
+	// unit when the value has none, or the unit rates are converted to
+	unit string
+}
+
+var sensorTypes = map[string]sensorType{
+	"Voltage":     {hwsensorsservice.ReadingTypeVolt, "V"},
+	"Current":     {hwsensorsservice.ReadingTypeCurrent, "A"},
+	"Power":       {hwsensorsservice.ReadingTypePower, "W"},
+	"Clock":       {hwsensorsservice.ReadingTypeClock, "MHz"},
+	"Frequency":   {hwsensorsservice.ReadingTypeClock, "Hz"},
+	"Temperature": {hwsensorsservice.ReadingTypeTemp, "°C"},
+	"Load":        {hwsensorsservice.ReadingTypeUsage, "%"},
+	"Level":       {hwsensorsservice.ReadingTypeUsage, "%"},
+	"Data":        {hwsensorsservice.ReadingTypeUsage, "GB"},
+	"SmallData":   {hwsensorsservice.ReadingTypeUsage, "MB"},
+	"Throughput":  {hwsensorsservice.ReadingTypeUsage, "KB/s"},
+	"Fan":         {hwsensorsservice.ReadingTypeFan, "RPM"},
+	"Control":     {hwsensorsservice.ReadingTypeOther, "%"},
+	"Flow":        {hwsensorsservice.ReadingTypeOther, "L/h"},
+	"Factor":      {hwsensorsservice.ReadingTypeOther, ""},
+	"Energy":      {hwsensorsservice.ReadingTypeOther, "mWh"},
+	"Noise":       {hwsensorsservice.ReadingTypeOther, "dBA"},
+	"TimeSpan":    {hwsensorsservice.ReadingTypeOther, ""},
+	"Timing":      {hwsensorsservice.ReadingTypeOther, "ns"},
+}
+
+// group names used by OpenHardwareMonitor, which has no sensor Type
+var groupTypes = map[string]string{
+	"Voltages":     "Voltage",
+	"Currents":     "Current",
+	"Powers":       "Power",
+	"Clocks":       "Clock",
+	"Frequencies":  "Frequency",
+	"Temperatures": "Temperature",
+	"Load":         "Load",
+	"Levels":       "Level",
+	"Data":         "Data",
+	"Throughput":   "Throughput",
+	"Fans":         "Fan",
+	"Controls":     "Control",
+	"Flows":        "Flow",
+	"Factors":      "Factor",
+}
+
+// throughput is formatted with a unit matching its magnitude,
+// converted to KB/s so a reading keeps a single unit
+var throughputScale = map[string]float64{
+	"B/s":  1e-3,
+	"KB/s": 1,
+	"MB/s": 1e3,
+	"GB/s": 1e6,
+}
+
+// Source polls data.json
+type Source struct {
+	url    string
+	client *http.Client
+}
+
+// NewSource creates a Source polling url, DefaultURL when empty
+func NewSource(url string) *Source {
+	if url == "" {
+		url = DefaultURL
+	}
+	return &Source{url: url, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// NewService creates a service for the sensors served at url
+func NewService(url string) *backend.Service {
+	return backend.NewService("lhm", NewSource(url))
+}
+
+// Read implements backend.Source, one sensor per hardware
+func (s *Source) Read() ([]backend.Sensor, error) {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return nil, fmt.Errorf("lhm: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("lhm: %s: %s", s.url, resp.Status)
+	}
+	var root node
+	if err := json.NewDecoder(resp.Body).Decode(&root); err != nil {
+		return nil, fmt.Errorf("lhm: decoding %s: %w", s.url, err)
+	}
+
+	var sensors []backend.Sensor
+	// root "Sensor" > computer > hardware
+	for _, computer := range root.Children {
+		for _, hw := range computer.Children {
+			sensors = flatten(sensors, computer.Text, hw, nil)
+		}
+	}
+	return sensors, nil
+}
+
+// flatten appends a sensor for hw and each of its sub hardware,
+// e.g. the super I/O chip of a mainboard
+func flatten(sensors []backend.Sensor, computer string, hw node, parents []string) []backend.Sensor {
+	names := append(parents[:len(parents):len(parents)], hw.Text)
+	sens := backend.Sensor{Name: hw.Text}
+	for _, child := range hw.Children {
+		group, ok := groupTypes[child.Text]
+		if !ok && !isGroup(child) {
+			sensors = flatten(sensors, computer, child, names)
+			continue
+		}
+		for _, leaf := range child.Children {
+			r, hwID, ok := parseReading(leaf, group)
+			if !ok {
+				continue
+			}
+			if sens.ID == "" && hwID != "" {
+				sens.ID = backend.SensorID("lhm", strings.TrimPrefix(hwID, "/"))
+			}
+			sens.Readings = append(sens.Readings, r)
+		}
+	}
+	if len(sens.Readings) == 0 {
+		return sensors
+	}
+	if sens.ID == "" {
+		// OpenHardwareMonitor has no sensor IDs, names are the next best
+		sens.ID = backend.SensorID("lhm", append([]string{computer}, names...)...)
+	}
+	return append(sensors, sens)
+}
+
+// isGroup a sensor type group has sensors as children, which have no
+// children themselves
+func isGroup(n node) bool {
+	if len(n.Children) == 0 {
+		return false
+	}
+	for _, c := range n.Children {
+		if len(c.Children) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// parseReading returns the reading of a sensor leaf and, when known,
+// the ID of its hardware, e.g. /lpc/nct6798d/0 for /lpc/nct6798d/0/voltage/0
+func parseReading(leaf node, group string) (backend.Reading, string, bool) {
+	typName := leaf.Type
+	if typName == "" {
+		typName = group
+	}
+	st, ok := sensorTypes[typName]
+	if !ok {
+		st = sensorType{hwsensorsservice.ReadingTypeOther, ""}
+	}
+
+	value, unit, ok := parseValue(leaf.Value)
+	if !ok {
+		return backend.Reading{}, "", false
+	}
+	min, _, minOK := parseValue(leaf.Min)
+	max, _, maxOK := parseValue(leaf.Max)
+	if unit == "" {
+		unit = st.unit
+	}
+	if typName == "Throughput" {
+		scale := func(v float64, u string) float64 {
+			if f, ok := throughputScale[u]; ok {
+				return v * f
+			}
+			return v
+		}
+		_, minUnit, _ := parseValue(leaf.Min)
+		_, maxUnit, _ := parseValue(leaf.Max)
+		value, min, max = scale(value, unit), scale(min, minUnit), scale(max, maxUnit)
+		unit = st.unit
+	}
+
+	key := leaf.SensorID
+	var hwID string
+	if key != "" {
+		hwID = path.Dir(path.Dir(key))
+	} else {
+		// unique within the hardware, like the reading IDs of HWiNFO
+		key = typName + "/" + leaf.Text
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+
+	return backend.Reading{
+		// positive so IDs read the same in every client
+		ID:        int32(h.Sum32() &^ (1 << 31)),
+		Type:      st.typ,
+		Label:     leaf.Text,
+		Unit:      unit,
+		Value:     value,
+		HasMinMax: minOK && maxOK,
+		ValueMin:  min,
+		ValueMax:  max,
+	}, hwID, true
+}
+
+// parseValue splits a formatted value like "45.5 °C" or "1,104 V",
+// depending on the locale of the server, into number and unit
+func parseValue(s string) (float64, string, bool) {
+	num, unit, _ := strings.Cut(strings.TrimSpace(s), " ")
+	if num == "" {
+		return 0, "", false
+	}
+	if !strings.Contains(num, ".") {
+		num = strings.Replace(num, ",", ".", 1)
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return v, strings.TrimSpace(unit), true
+}
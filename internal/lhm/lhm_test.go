@@ -0,0 +1,103 @@
+package lhm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+func TestRead(t *testing.T) {
+	ts := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer ts.Close()
+
+	sensors, err := NewSource(ts.URL + "/data.json").Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	var ids []string
+	for _, s := range sensors {
+		ids = append(ids, s.ID)
+	}
+	want := []string{"lhm:lpc/nct6798d/0", "lhm:amdcpu/0", "lhm:nic/%7B1234%7D"}
+	if len(ids) != len(want) {
+		t.Fatalf("sensors = %q, want %q", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("sensors = %q, want %q", ids, want)
+		}
+	}
+	if sensors[0].Name != "Nuvoton NCT6798D" {
+		t.Errorf("name = %q", sensors[0].Name)
+	}
+
+	tests := []struct {
+		got      float64
+		min, max float64
+		sensor   int
+		reading  int
+		label    string
+		typ      hwsensorsservice.ReadingType
+		unit     string
+	}{
+		{1.104, 0.984, 1.456, 0, 0, "Vcore", hwsensorsservice.ReadingTypeVolt, "V"},
+		{1250, 650, 1800, 0, 1, "CPU Fan", hwsensorsservice.ReadingTypeFan, "RPM"},
+		{45.5, 35.4, 81, 1, 0, "Core (Tctl/Tdie)", hwsensorsservice.ReadingTypeTemp, "°C"},
+		{12.3, 0.4, 100, 1, 1, "CPU Total", hwsensorsservice.ReadingTypeUsage, "%"},
+		{2500, 0, 11200, 2, 0, "Download Speed", hwsensorsservice.ReadingTypeUsage, "KB/s"},
+	}
+	for _, tt := range tests {
+		r := sensors[tt.sensor].Readings[tt.reading]
+		if r.Label != tt.label || r.Type != tt.typ || r.Unit != tt.unit || r.Value != tt.got ||
+			!r.HasMinMax || r.ValueMin != tt.min || r.ValueMax != tt.max {
+			t.Errorf("reading = %+v, want %s %v %s %v (%v-%v)", r, tt.label, tt.typ, tt.unit, tt.got, tt.min, tt.max)
+		}
+	}
+	// the core load without a value is skipped
+	if n := len(sensors[1].Readings); n != 2 {
+		t.Errorf("got %d CPU readings, want 2", n)
+	}
+
+	again, err := NewSource(ts.URL + "/data.json").Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if again[0].Readings[1].ID != sensors[0].Readings[1].ID || sensors[0].Readings[0].ID == sensors[0].Readings[1].ID {
+		t.Error("reading IDs not stable and unique")
+	}
+}
+
+func TestReadOpenHardwareMonitor(t *testing.T) {
+	ts := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer ts.Close()
+
+	sensors, err := NewSource(ts.URL + "/ohm.json").Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(sensors) != 1 || sensors[0].ID != "lhm:DESKTOP-2/Intel Core i7-8700K" {
+		t.Fatalf("sensors = %+v", sensors)
+	}
+	readings := sensors[0].Readings
+	if len(readings) != 2 {
+		t.Fatalf("got %d readings, want 2", len(readings))
+	}
+	// same label, different groups
+	if readings[0].ID == readings[1].ID {
+		t.Error("reading IDs collide")
+	}
+	if readings[0].Type != hwsensorsservice.ReadingTypeClock || readings[1].Type != hwsensorsservice.ReadingTypeTemp {
+		t.Errorf("types = %v %v", readings[0].Type, readings[1].Type)
+	}
+}
+
+func TestReadError(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	if _, err := NewSource(ts.URL).Read(); err == nil {
+		t.Error("expected error for 404")
+	}
+}
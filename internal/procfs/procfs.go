@@ -0,0 +1,362 @@
+// Package procfs derives system load from Linux /proc: CPU usage,
+// memory usage, disk I/O and network throughput. Usage and rates are
+// computed from the counters of consecutive polls.
+package procfs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/backend"
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+// DefaultRoot procfs mount point
+const DefaultRoot = "/proc"
+
+// DefaultSysRoot sysfs mount point, telling partitions from disks
+const DefaultSysRoot = "/sys"
+
+// diskstats sectors are always 512 bytes, regardless of the device
+const sectorSize = 512
+
+const usage = hwsensorsservice.ReadingTypeUsage
+
+// Source reads counters from a procfs root
+type Source struct {
+	root string
+	sys  string
+	now  func() time.Time
+
+	mu       sync.Mutex
+	last     time.Time
+	cpu      map[string]cpuTimes
+	counters map[string]uint64
+}
+
+// NewSource creates a Source reading root and sysRoot,
+// DefaultRoot and DefaultSysRoot when empty
+func NewSource(root, sysRoot string) *Source {
+	if root == "" {
+		root = DefaultRoot
+	}
+	if sysRoot == "" {
+		sysRoot = DefaultSysRoot
+	}
+	return &Source{root: root, sys: sysRoot, now: time.Now}
+}
+
+// NewService creates a service for the system load under root and sysRoot
+func NewService(root, sysRoot string) *backend.Service {
+	return backend.NewService("proc", NewSource(root, sysRoot))
+}
+
+// Read implements backend.Source. CPU usage of the first poll is the
+// average since boot, disk and network rates are 0 until the second.
+func (s *Source) Read() ([]backend.Sensor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	cpu, err := s.readStat()
+	if err != nil {
+		return nil, err
+	}
+	mem, err := s.readMeminfo()
+	if err != nil {
+		return nil, err
+	}
+	disks, err := s.readDiskstats()
+	if err != nil {
+		return nil, err
+	}
+	nets, err := s.readNetDev()
+	if err != nil {
+		return nil, err
+	}
+
+	sensors := []backend.Sensor{s.cpuSensor(cpu), memSensor(mem)}
+	counters := make(map[string]uint64)
+	rate := func(key string, v uint64, scale float64) float64 {
+		counters[key] = v
+		prev, ok := s.counters[key]
+		dt := now.Sub(s.last).Seconds()
+		// counters reset when a device is re-added
+		if !ok || dt <= 0 || v < prev {
+			return 0
+		}
+		return float64(v-prev) * scale / dt
+	}
+	for _, d := range disks {
+		key := "disk/" + d.name
+		sensors = append(sensors, backend.Sensor{
+			ID:   backend.SensorID("proc", key),
+			Name: "Disk " + d.name,
+			Readings: []backend.Reading{
+				{ID: int32(usage) << 24, Type: usage, Label: "Read Rate", Unit: "MB/s", Value: rate(key+"/read", d.sectorsRead, sectorSize/1e6)},
+				{ID: int32(usage)<<24 | 1, Type: usage, Label: "Write Rate", Unit: "MB/s", Value: rate(key+"/write", d.sectorsWritten, sectorSize/1e6)},
+			},
+		})
+	}
+	for _, n := range nets {
+		key := "net/" + n.name
+		sensors = append(sensors, backend.Sensor{
+			ID:   backend.SensorID("proc", key),
+			Name: "Network " + n.name,
+			Readings: []backend.Reading{
+				{ID: int32(usage) << 24, Type: usage, Label: "Current DL rate", Unit: "KB/s", Value: rate(key+"/rx", n.rxBytes, 1e-3)},
+				{ID: int32(usage)<<24 | 1, Type: usage, Label: "Current UP rate", Unit: "KB/s", Value: rate(key+"/tx", n.txBytes, 1e-3)},
+			},
+		})
+	}
+
+	s.last = now
+	s.cpu = cpu.times
+	s.counters = counters
+	return sensors, nil
+}
+
+func (s *Source) open(name string) (*os.File, error) {
+	f, err := os.Open(filepath.Join(s.root, name))
+	if err != nil {
+		return nil, fmt.Errorf("procfs: %w", err)
+	}
+	return f, nil
+}
+
+// cpuTimes jiffies spent busy and in total
+type cpuTimes struct {
+	busy, total uint64
+}
+
+type cpuStat struct {
+	// names in file order, "cpu" first
+	names []string
+	times map[string]cpuTimes
+}
+
+func (s *Source) readStat() (cpuStat, error) {
+	f, err := s.open("stat")
+	if err != nil {
+		return cpuStat{}, err
+	}
+	defer f.Close()
+
+	st := cpuStat{times: make(map[string]cpuTimes)}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		var t cpuTimes
+		// user nice system idle iowait irq softirq steal, guest time
+		// is already part of user and nice
+		for i, f := range fields[1:] {
+			if i >= 8 {
+				break
+			}
+			v, err := strconv.ParseUint(f, 10, 64)
+			if err != nil {
+				return cpuStat{}, fmt.Errorf("procfs: stat %s: %w", fields[0], err)
+			}
+			t.total += v
+			if i != 3 && i != 4 {
+				t.busy += v
+			}
+		}
+		st.names = append(st.names, fields[0])
+		st.times[fields[0]] = t
+	}
+	if err := sc.Err(); err != nil {
+		return cpuStat{}, fmt.Errorf("procfs: stat: %w", err)
+	}
+	return st, nil
+}
+
+func (s *Source) cpuSensor(st cpuStat) backend.Sensor {
+	sens := backend.Sensor{ID: backend.SensorID("proc", "cpu"), Name: "CPU"}
+	for _, name := range st.names {
+		t := st.times[name]
+		prev := s.cpu[name]
+		value := 0.
+		if t.total > prev.total && t.busy >= prev.busy {
+			value = 100 * float64(t.busy-prev.busy) / float64(t.total-prev.total)
+		}
+		r := backend.Reading{Type: usage, Label: "Total CPU Usage", Unit: "%", Value: value}
+		if name != "cpu" {
+			n, err := strconv.Atoi(strings.TrimPrefix(name, "cpu"))
+			if err != nil {
+				continue
+			}
+			r.ID = int32(usage)<<24 | int32(n+1)
+			r.Label = fmt.Sprintf("Core %d Usage", n)
+		} else {
+			r.ID = int32(usage) << 24
+		}
+		sens.Readings = append(sens.Readings, r)
+	}
+	return sens
+}
+
+func (s *Source) readMeminfo() (map[string]uint64, error) {
+	f, err := s.open("meminfo")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mem := make(map[string]uint64)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// MemTotal:       32768000 kB
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		mem[strings.TrimSuffix(fields[0], ":")] = v
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("procfs: meminfo: %w", err)
+	}
+	return mem, nil
+}
+
+func memSensor(mem map[string]uint64) backend.Sensor {
+	total := mem["MemTotal"]
+	avail, ok := mem["MemAvailable"]
+	if !ok {
+		// kernels before 3.14
+		avail = mem["MemFree"] + mem["Buffers"] + mem["Cached"]
+	}
+	used := total - avail
+	if avail > total {
+		used = 0
+	}
+	load := 0.
+	if total > 0 {
+		load = 100 * float64(used) / float64(total)
+	}
+	swapUsed := mem["SwapTotal"] - mem["SwapFree"]
+	if mem["SwapFree"] > mem["SwapTotal"] {
+		swapUsed = 0
+	}
+	return backend.Sensor{
+		ID:   backend.SensorID("proc", "memory"),
+		Name: "Memory",
+		Readings: []backend.Reading{
+			{ID: int32(usage) << 24, Type: usage, Label: "Physical Memory Used", Unit: "MB", Value: float64(used) / 1024},
+			{ID: int32(usage)<<24 | 1, Type: usage, Label: "Physical Memory Available", Unit: "MB", Value: float64(avail) / 1024},
+			{ID: int32(usage)<<24 | 2, Type: usage, Label: "Physical Memory Load", Unit: "%", Value: load},
+			{ID: int32(usage)<<24 | 3, Type: usage, Label: "Swap Used", Unit: "MB", Value: float64(swapUsed) / 1024},
+		},
+	}
+}
+
+type diskStat struct {
+	name                        string
+	sectorsRead, sectorsWritten uint64
+}
+
+func (s *Source) readDiskstats() ([]diskStat, error) {
+	f, err := s.open("diskstats")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var disks []diskStat
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// major minor name reads merged sectors ms writes merged sectors ...
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		name := fields[2]
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+			continue
+		}
+		read, err := strconv.ParseUint(fields[5], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("procfs: diskstats %s: %w", name, err)
+		}
+		written, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("procfs: diskstats %s: %w", name, err)
+		}
+		disks = append(disks, diskStat{name: name, sectorsRead: read, sectorsWritten: written})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("procfs: diskstats: %w", err)
+	}
+
+	// partitions would count their I/O twice
+	var whole []diskStat
+	for _, d := range disks {
+		if !s.partition(d.name) {
+			whole = append(whole, d)
+		}
+	}
+	return whole, nil
+}
+
+// partition whether the block device name is a partition of a disk.
+// Names alone can not tell, nvme0n10 is a disk and sda10 a partition.
+// Without sysfs every device is taken for a disk.
+func (s *Source) partition(name string) bool {
+	_, err := os.Stat(filepath.Join(s.sys, "class", "block", name, "partition"))
+	return err == nil
+}
+
+type netStat struct {
+	name             string
+	rxBytes, txBytes uint64
+}
+
+func (s *Source) readNetDev() ([]netStat, error) {
+	f, err := s.open("net/dev")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var nets []netStat
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// two header lines, then
+		//   eth0: rxbytes packets errs drop fifo frame compressed multicast txbytes ...
+		name, rest, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		fields := strings.Fields(rest)
+		if name == "lo" || len(fields) < 9 {
+			continue
+		}
+		rx, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("procfs: net/dev %s: %w", name, err)
+		}
+		tx, err := strconv.ParseUint(fields[8], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("procfs: net/dev %s: %w", name, err)
+		}
+		nets = append(nets, netStat{name: name, rxBytes: rx, txBytes: tx})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("procfs: net/dev: %w", err)
+	}
+	return nets, nil
+}
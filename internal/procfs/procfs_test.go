@@ -0,0 +1,157 @@
+package procfs
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const meminfo = `MemTotal:       16384000 kB
+MemFree:         1024000 kB
+MemAvailable:    4096000 kB
+SwapTotal:       2048000 kB
+SwapFree:        1024000 kB
+`
+
+func writeProc(t *testing.T, root, stat, diskstats, netdev string) {
+	t.Helper()
+	files := map[string]string{
+		"stat":      stat,
+		"meminfo":   meminfo,
+		"diskstats": diskstats,
+		"net/dev":   netdev,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// writeSys creates the sysfs block devices, partitions have a
+// partition file holding their number
+func writeSys(t *testing.T, root string, disks, partitions []string) {
+	t.Helper()
+	for _, name := range append(disks, partitions...) {
+		if err := os.MkdirAll(filepath.Join(root, "class", "block", name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, name := range partitions {
+		path := filepath.Join(root, "class", "block", name, "partition")
+		if err := os.WriteFile(path, []byte(fmt.Sprintln(i+1)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const netHeader = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+`
+
+func TestRead(t *testing.T) {
+	root := t.TempDir()
+	writeProc(t, root,
+		"cpu  100 0 100 800 0 0 0 0 0 0\ncpu0 50 0 50 400 0 0 0 0 0 0\ncpu1 50 0 50 400 0 0 0 0 0 0\nintr 1 2 3\n",
+		"   8       0 sda 10 0 2000 0 10 0 4000 0 0 0 0\n   8       1 sda1 10 0 2000 0 10 0 4000 0 0 0 0\n   7       0 loop0 1 0 8 0 0 0 0 0 0 0 0\n 259       0 nvme0n1 1 0 100 0 1 0 100 0 0 0 0\n 259       1 nvme0n1p1 1 0 100 0 1 0 100 0 0 0 0\n",
+		netHeader+"    lo: 500 5 0 0 0 0 0 0 500 5 0 0 0 0 0 0\n  eth0: 1000 10 0 0 0 0 0 0 2000 20 0 0 0 0 0 0\n",
+	)
+
+	sys := t.TempDir()
+	writeSys(t, sys, []string{"sda", "loop0", "nvme0n1"}, []string{"sda1", "nvme0n1p1"})
+
+	now := time.Unix(1000, 0)
+	src := NewSource(root, sys)
+	src.now = func() time.Time { return now }
+
+	sensors, err := src.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	var ids []string
+	for _, s := range sensors {
+		ids = append(ids, s.ID)
+	}
+	want := []string{"proc:cpu", "proc:memory", "proc:disk/sda", "proc:disk/nvme0n1", "proc:net/eth0"}
+	if len(ids) != len(want) {
+		t.Fatalf("sensors = %q, want %q", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("sensors = %q, want %q", ids, want)
+		}
+	}
+	// since boot on the first poll
+	if v := sensors[0].Readings[0].Value; v != 20 {
+		t.Errorf("first total CPU usage = %v, want 20", v)
+	}
+	mem := sensors[1].Readings
+	if mem[0].Value != 12000 || mem[2].Value != 75 || mem[3].Value != 1000 {
+		t.Errorf("memory used %v load %v swap %v", mem[0].Value, mem[2].Value, mem[3].Value)
+	}
+
+	now = now.Add(2 * time.Second)
+	writeProc(t, root,
+		"cpu  200 0 200 900 0 0 0 0 0 0\ncpu0 150 0 50 400 0 0 0 0 0 0\ncpu1 50 0 150 500 0 0 0 0 0 0\n",
+		"   8       0 sda 20 0 6000 0 10 0 4000 0 0 0 0\n 259       0 nvme0n1 1 0 100 0 1 0 100 0 0 0 0\n",
+		netHeader+"  eth0: 3001000 10 0 0 0 0 0 0 2000 20 0 0 0 0 0 0\n",
+	)
+	sensors, err = src.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"total CPU", sensors[0].Readings[0].Value, 200. / 300 * 100},
+		{"core 0", sensors[0].Readings[1].Value, 100},
+		{"core 1", sensors[0].Readings[2].Value, 50},
+		{"sda read MB/s", sensors[2].Readings[0].Value, 4000 * 512 / 1e6 / 2},
+		{"sda write MB/s", sensors[2].Readings[1].Value, 0},
+		{"eth0 download KB/s", sensors[4].Readings[0].Value, 1500},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if sensors[0].Readings[2].Label != "Core 1 Usage" {
+		t.Errorf("label = %q", sensors[0].Readings[2].Label)
+	}
+}
+
+func TestReadPartitions(t *testing.T) {
+	root := t.TempDir()
+	// disks named like partitions of the disks before them
+	names := []string{"sda", "sda10", "dm-1", "dm-10", "nbd1", "nbd10", "nvme0n1", "nvme0n1p1", "nvme0n10", "mmcblk0", "mmcblk0p1"}
+	var diskstats string
+	for i, name := range names {
+		diskstats += fmt.Sprintf(" 8 %d %s 1 0 100 0 1 0 100 0 0 0 0\n", i, name)
+	}
+	writeProc(t, root, "cpu  100 0 100 800 0 0 0 0 0 0\n", diskstats, netHeader)
+	sys := t.TempDir()
+	writeSys(t, sys, []string{"sda", "dm-1", "dm-10", "nbd1", "nbd10", "nvme0n1", "nvme0n10", "mmcblk0"}, []string{"sda10", "nvme0n1p1", "mmcblk0p1"})
+
+	src := NewSource(root, sys)
+	sensors, err := src.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	var got []string
+	for _, s := range sensors[2:] {
+		got = append(got, s.Name)
+	}
+	want := []string{"Disk sda", "Disk dm-1", "Disk dm-10", "Disk nbd1", "Disk nbd10", "Disk nvme0n1", "Disk nvme0n10", "Disk mmcblk0"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("disks = %q, want %q", got, want)
+	}
+}
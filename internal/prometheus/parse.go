@@ -0,0 +1,252 @@
+package prometheus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sample a series and its value scraped from an exposition
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// String the series in exposition syntax, labels sorted by name
+func (s Sample) String() string {
+	if len(s.Labels) == 0 {
+		return s.Name
+	}
+	keys := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(s.Name)
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%q", k, s.Labels[k])
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// ParseText parses the Prometheus text exposition format,
+// comments and timestamps are ignored
+func ParseText(r io.Reader) ([]Sample, error) {
+	var samples []Sample
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		s, err := parseSample(line)
+		if err != nil {
+			return nil, fmt.Errorf("prometheus: line %d: %w", n, err)
+		}
+		samples = append(samples, s)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("prometheus: %w", err)
+	}
+	return samples, nil
+}
+
+// parseSample name{label="value",...} value [timestamp]
+func parseSample(line string) (Sample, error) {
+	i := strings.IndexAny(line, "{ \t")
+	if i <= 0 {
+		return Sample{}, fmt.Errorf("missing value in %q", line)
+	}
+	s := Sample{Name: line[:i]}
+	rest := line[i:]
+	if rest[0] == '{' {
+		labels, n, err := parseLabels(rest)
+		if err != nil {
+			return Sample{}, err
+		}
+		s.Labels = labels
+		rest = rest[n:]
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return Sample{}, fmt.Errorf("missing value in %q", line)
+	}
+	v, err := parseFloat(fields[0])
+	if err != nil {
+		return Sample{}, err
+	}
+	s.Value = v
+	return s, nil
+}
+
+func parseFloat(s string) (float64, error) {
+	switch s {
+	case "NaN":
+		return math.NaN(), nil
+	case "+Inf":
+		return math.Inf(1), nil
+	case "-Inf":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseLabels parses {label="value",...} at the start of s and
+// returns the labels and the length of the label set
+func parseLabels(s string) (map[string]string, int, error) {
+	labels := make(map[string]string)
+	i := 1
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == ',') {
+			i++
+		}
+		if i < len(s) && s[i] == '}' {
+			return labels, i + 1, nil
+		}
+		eq := strings.IndexByte(s[i:], '=')
+		if eq < 0 {
+			return nil, 0, fmt.Errorf("invalid label set %q", s)
+		}
+		name := strings.TrimSpace(s[i : i+eq])
+		i += eq + 1
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		value, n, err := parseQuoted(s[i:])
+		if err != nil {
+			return nil, 0, err
+		}
+		labels[name] = value
+		i += n
+	}
+}
+
+// parseQuoted parses a double quoted label value with \\, \" and \n
+// escapes at the start of s, returns the value and its quoted length
+func parseQuoted(s string) (string, int, error) {
+	if s == "" || s[0] != '"' {
+		return "", 0, fmt.Errorf("label value not quoted in %q", s)
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(s) {
+				return "", 0, fmt.Errorf("unterminated label value %q", s)
+			}
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated label value %q", s)
+}
+
+// matcher a label matcher of a selector
+type matcher struct {
+	name  string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (m matcher) matches(labels map[string]string) bool {
+	v := labels[m.name]
+	switch m.op {
+	case "=":
+		return v == m.value
+	case "!=":
+		return v != m.value
+	case "=~":
+		return m.re.MatchString(v)
+	default: // "!~"
+		return !m.re.MatchString(v)
+	}
+}
+
+// Selector selects series like a PromQL instant vector selector,
+// e.g. node_hwmon_temp_celsius{chip=~"pci.*",sensor!="temp2"}
+type Selector struct {
+	name     string
+	matchers []matcher
+}
+
+var matcherRe = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*`)
+
+// ParseSelector parses a series selector, regular expressions
+// are fully anchored as in PromQL
+func ParseSelector(s string) (*Selector, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexByte(s, '{')
+	if i < 0 {
+		i = len(s)
+	}
+	sel := &Selector{name: strings.TrimSpace(s[:i])}
+	if sel.name == "" {
+		return nil, fmt.Errorf("prometheus: selector %q has no metric name", s)
+	}
+	rest := s[i:]
+	if rest == "" {
+		return sel, nil
+	}
+	rest = rest[1:]
+	for {
+		rest = strings.TrimLeft(rest, " ,")
+		if strings.HasPrefix(rest, "}") {
+			if strings.TrimSpace(rest[1:]) != "" {
+				return nil, fmt.Errorf("prometheus: trailing %q in selector %q", rest[1:], s)
+			}
+			return sel, nil
+		}
+		m := matcherRe.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("prometheus: invalid matcher in selector %q", s)
+		}
+		rest = rest[len(m[0]):]
+		value, n, err := parseQuoted(rest)
+		if err != nil {
+			return nil, fmt.Errorf("prometheus: selector %q: %w", s, err)
+		}
+		rest = rest[n:]
+		mt := matcher{name: m[1], op: m[2], value: value}
+		if mt.op == "=~" || mt.op == "!~" {
+			if mt.re, err = regexp.Compile("^(?:" + value + ")$"); err != nil {
+				return nil, fmt.Errorf("prometheus: selector %q: %w", s, err)
+			}
+		}
+		sel.matchers = append(sel.matchers, mt)
+	}
+}
+
+// Matches sample s is selected
+func (sel *Selector) Matches(s Sample) bool {
+	if s.Name != sel.name {
+		return false
+	}
+	for _, m := range sel.matchers {
+		if !m.matches(s.Labels) {
+			return false
+		}
+	}
+	return true
+}
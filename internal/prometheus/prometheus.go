@@ -0,0 +1,238 @@
+// Package prometheus scrapes Prometheus exporters, e.g. node_exporter,
+// and serves selected series as sensors, one sensor per target
+package prometheus
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/backend"
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+// Config targets to scrape, e.g.
+//
+//	{"targets": [{
+//		"name": "lab1",
+//		"url": "http://lab1:9100/metrics",
+//		"metrics": [
+//			{"selector": "node_hwmon_temp_celsius{chip=~\"platform_coretemp.*\"}", "label": "sensor"},
+//			{"selector": "node_load1"}
+//		]
+//	}]}
+type Config struct {
+	Targets []Target `json:"targets"`
+}
+
+// Target an exporter endpoint
+type Target struct {
+	// Name of the sensor the series are served as
+	Name    string   `json:"name"`
+	URL     string   `json:"url"`
+	Metrics []Metric `json:"metrics"`
+}
+
+// Metric series served as readings
+type Metric struct {
+	// Selector PromQL style series selector, see ParseSelector
+	Selector string `json:"selector"`
+	// Label optional series label naming the readings, the series
+	// itself names them when unset or missing
+	Label string `json:"label,omitempty"`
+
+	sel *Selector
+}
+
+// LoadConfig reads a JSON Config from path
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("prometheus: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("prometheus: %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// unit of a metric, taken from the suffix its name ends with
+type unit struct {
+	suffix string
+	typ    hwsensorsservice.ReadingType
+	unit   string
+	scale  float64
+}
+
+// ordered so longer suffixes match first
+var units = []unit{
+	{"_celsius", hwsensorsservice.ReadingTypeTemp, "°C", 1},
+	{"_volts", hwsensorsservice.ReadingTypeVolt, "V", 1},
+	{"_amperes", hwsensorsservice.ReadingTypeCurrent, "A", 1},
+	{"_watts", hwsensorsservice.ReadingTypePower, "W", 1},
+	{"_rpm", hwsensorsservice.ReadingTypeFan, "RPM", 1},
+	{"_hertz", hwsensorsservice.ReadingTypeClock, "MHz", 1e-6},
+	{"_ratio", hwsensorsservice.ReadingTypeUsage, "%", 100},
+	{"_percent", hwsensorsservice.ReadingTypeUsage, "%", 1},
+	{"_bytes", hwsensorsservice.ReadingTypeUsage, "MB", 1.0 / (1 << 20)},
+	{"_seconds", hwsensorsservice.ReadingTypeOther, "s", 1},
+}
+
+func unitFor(name string) unit {
+	name = strings.TrimSuffix(name, "_total")
+	for _, u := range units {
+		if strings.HasSuffix(name, u.suffix) {
+			return u
+		}
+	}
+	return unit{typ: hwsensorsservice.ReadingTypeOther, scale: 1}
+}
+
+// Source scrapes the targets of a Config
+type Source struct {
+	targets []Target
+	client  *http.Client
+}
+
+// NewSource creates a Source, selectors of cfg are parsed up front
+func NewSource(cfg *Config) (*Source, error) {
+	targets := make([]Target, len(cfg.Targets))
+	seen := make(map[string]bool)
+	for i, t := range cfg.Targets {
+		if t.Name == "" || t.URL == "" {
+			return nil, fmt.Errorf("prometheus: target %d needs a name and url", i)
+		}
+		// the name is the sensor ID, two targets cannot share it
+		if seen[t.Name] {
+			return nil, fmt.Errorf("prometheus: duplicate target name %q", t.Name)
+		}
+		seen[t.Name] = true
+		t.Metrics = append([]Metric(nil), t.Metrics...)
+		for j := range t.Metrics {
+			sel, err := ParseSelector(t.Metrics[j].Selector)
+			if err != nil {
+				return nil, err
+			}
+			t.Metrics[j].sel = sel
+		}
+		targets[i] = t
+	}
+	return &Source{targets: targets, client: &http.Client{Timeout: 5 * time.Second}}, nil
+}
+
+// NewService creates a service for the targets of cfg
+func NewService(cfg *Config) (*backend.Service, error) {
+	src, err := NewSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return backend.NewService("prometheus", src), nil
+}
+
+// Read implements backend.Source. Targets are scraped concurrently so
+// a Read takes at most one scrape timeout. Targets failing to scrape
+// are served with the error so their tiles show them unavailable, Read
+// only fails when every target failed.
+func (s *Source) Read() ([]backend.Sensor, error) {
+	sensors := make([]backend.Sensor, len(s.targets))
+	errs := make([]error, len(s.targets))
+	var wg sync.WaitGroup
+	for i, t := range s.targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			sensors[i], errs[i] = s.scrape(t)
+		}(i, t)
+	}
+	wg.Wait()
+
+	var lastErr error
+	ok := false
+	for i, t := range s.targets {
+		if err := errs[i]; err != nil {
+			log.Printf("prometheus scrape %s failed: %v\n", t.Name, err)
+			lastErr = fmt.Errorf("prometheus: scrape %s: %w", t.Name, err)
+			sensors[i] = backend.Sensor{ID: sensorID(t), Name: t.Name, Err: lastErr}
+		} else {
+			ok = true
+		}
+	}
+	if !ok && lastErr != nil {
+		return nil, lastErr
+	}
+	return sensors, nil
+}
+
+func sensorID(t Target) string {
+	return backend.SensorID("prom", t.Name)
+}
+
+func (s *Source) scrape(t Target) (backend.Sensor, error) {
+	req, err := http.NewRequest(http.MethodGet, t.URL, nil)
+	if err != nil {
+		return backend.Sensor{}, err
+	}
+	// protobuf and OpenMetrics are not supported
+	req.Header.Set("Accept", "text/plain;version=0.0.4")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return backend.Sensor{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return backend.Sensor{}, fmt.Errorf("%s: %s", t.URL, resp.Status)
+	}
+	samples, err := ParseText(resp.Body)
+	if err != nil {
+		return backend.Sensor{}, err
+	}
+
+	sens := backend.Sensor{ID: sensorID(t), Name: t.Name}
+	seen := make(map[int32]bool)
+	for _, m := range t.Metrics {
+		for _, sample := range samples {
+			if !m.sel.Matches(sample) || math.IsNaN(sample.Value) {
+				continue
+			}
+			series := sample.String()
+			id := seriesID(t.Name, series)
+			// a series selected by several metrics is served once
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			label := series
+			if v := sample.Labels[m.Label]; m.Label != "" && v != "" {
+				label = v
+			}
+			u := unitFor(sample.Name)
+			sens.Readings = append(sens.Readings, backend.Reading{
+				ID:    id,
+				Type:  u.typ,
+				Label: label,
+				Unit:  u.unit,
+				Value: sample.Value * u.scale,
+			})
+		}
+	}
+	return sens, nil
+}
+
+// seriesID stable, positive reading ID of a series of target,
+// the same series of different targets gets different IDs
+func seriesID(target, series string) int32 {
+	h := fnv.New32a()
+	h.Write([]byte(target))
+	h.Write([]byte{0})
+	h.Write([]byte(series))
+	return int32(h.Sum32() &^ (1 << 31))
+}
@@ -0,0 +1,189 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shayne/hwinfo-streamdeck/internal/backend"
+	hwsensorsservice "github.com/shayne/hwinfo-streamdeck/pkg/service"
+)
+
+func TestParseText(t *testing.T) {
+	f, err := os.Open("testdata/metrics.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	samples, err := ParseText(f)
+	if err != nil {
+		t.Fatalf("ParseText: %v", err)
+	}
+	if len(samples) != 8 {
+		t.Fatalf("got %d samples, want 8", len(samples))
+	}
+	if s := samples[6]; s.Name != "node_load1" || s.Value != 0.52 {
+		t.Errorf("sample = %+v", s)
+	}
+	if got := samples[7].Labels["path"]; got != "C:\\temp \"x\"\nline" {
+		t.Errorf("escaped label = %q", got)
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	s := Sample{Name: "node_hwmon_temp_celsius", Labels: map[string]string{"chip": "platform_coretemp_0", "sensor": "temp1"}}
+	tests := []struct {
+		sel  string
+		want bool
+	}{
+		{"node_hwmon_temp_celsius", true},
+		{"node_hwmon_fan_rpm", false},
+		{`node_hwmon_temp_celsius{chip="platform_coretemp_0"}`, true},
+		{`node_hwmon_temp_celsius{chip=~"platform_.*", sensor!="temp2"}`, true},
+		{`node_hwmon_temp_celsius{chip=~"platform"}`, false},
+		{`node_hwmon_temp_celsius{sensor!~"temp[0-9]"}`, false},
+	}
+	for _, tt := range tests {
+		sel, err := ParseSelector(tt.sel)
+		if err != nil {
+			t.Errorf("ParseSelector(%s): %v", tt.sel, err)
+			continue
+		}
+		if got := sel.Matches(s); got != tt.want {
+			t.Errorf("%s matches = %v, want %v", tt.sel, got, tt.want)
+		}
+	}
+	for _, bad := range []string{`{chip="x"}`, `m{chip=x}`, `m{chip="x"`, `m{chip=~"("}`} {
+		if _, err := ParseSelector(bad); err == nil {
+			t.Errorf("ParseSelector(%s) expected error", bad)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	ts := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer ts.Close()
+
+	src, err := NewSource(&Config{Targets: []Target{
+		{
+			Name: "lab1",
+			URL:  ts.URL + "/metrics.txt",
+			Metrics: []Metric{
+				{Selector: `node_hwmon_temp_celsius{chip=~"platform_coretemp.*"}`, Label: "sensor"},
+				{Selector: `node_hwmon_fan_rpm`},
+				{Selector: `node_cpu_scaling_frequency_hertz`},
+				{Selector: `node_memory_MemAvailable_bytes`},
+			},
+		},
+		{Name: "down", URL: ts.URL + "/missing", Metrics: []Metric{{Selector: "up"}}},
+	}})
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	sensors, err := src.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	// the failing target is kept with its error
+	if len(sensors) != 2 || sensors[0].ID != "prom:lab1" || sensors[0].Err != nil ||
+		sensors[1].ID != "prom:down" || sensors[1].Err == nil || len(sensors[1].Readings) != 0 {
+		t.Fatalf("sensors = %+v", sensors)
+	}
+	want := []struct {
+		label string
+		typ   hwsensorsservice.ReadingType
+		unit  string
+		value float64
+	}{
+		{"temp1", hwsensorsservice.ReadingTypeTemp, "°C", 45},
+		{"temp2", hwsensorsservice.ReadingTypeTemp, "°C", 43.5},
+		{`node_hwmon_fan_rpm{chip="nct6798",sensor="fan2"}`, hwsensorsservice.ReadingTypeFan, "RPM", 1250},
+		{`node_cpu_scaling_frequency_hertz{cpu="0"}`, hwsensorsservice.ReadingTypeClock, "MHz", 3600},
+		{"node_memory_MemAvailable_bytes", hwsensorsservice.ReadingTypeUsage, "MB", 4096},
+	}
+	readings := sensors[0].Readings
+	if len(readings) != len(want) {
+		t.Fatalf("got %d readings, want %d", len(readings), len(want))
+	}
+	for i, w := range want {
+		r := readings[i]
+		if r.Label != w.label || r.Type != w.typ || r.Unit != w.unit || r.Value != w.value {
+			t.Errorf("reading %d = %s %v %s %v, want %s %v %s %v", i, r.Label, r.Type, r.Unit, r.Value, w.label, w.typ, w.unit, w.value)
+		}
+	}
+
+	// its tiles see it unavailable, not their readings gone
+	svc := backend.NewService("prometheus", src)
+	if err := svc.Poll(); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	results, err := svc.ReadingsByKeys(context.Background(), []hwsensorsservice.ReadingKey{{SensorID: "prom:down", ReadingID: 1}})
+	if err != nil {
+		t.Fatalf("ReadingsByKeys: %v", err)
+	}
+	if err := results[0].Err; !errors.Is(err, hwsensorsservice.ErrUnavailable) || errors.Is(err, hwsensorsservice.ErrReadingNotFound) {
+		t.Errorf("down reading err = %v, want ErrUnavailable", err)
+	}
+	st, err := svc.Status(context.Background())
+	if err != nil || st.State != hwsensorsservice.StateActive || st.LastError == "" {
+		t.Errorf("Status = %+v, %v, want active with the scrape error", st, err)
+	}
+
+	if seriesID("lab1", "up") == seriesID("lab2", "up") {
+		t.Error("series of different targets share an ID")
+	}
+
+	src, err = NewSource(&Config{Targets: []Target{{Name: "down", URL: ts.URL + "/missing"}}})
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	if _, err := src.Read(); err == nil {
+		t.Error("expected error when every target is down")
+	}
+}
+
+func TestNewSourceDuplicateName(t *testing.T) {
+	_, err := NewSource(&Config{Targets: []Target{
+		{Name: "lab1", URL: "http://a:9100/metrics"},
+		{Name: "lab1", URL: "http://b:9100/metrics"},
+	}})
+	if err == nil {
+		t.Error("expected error for targets sharing a name")
+	}
+}
+
+func TestReadConcurrent(t *testing.T) {
+	const delay = 200 * time.Millisecond
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Write([]byte("up 1\n"))
+	}))
+	defer ts.Close()
+
+	var targets []Target
+	for _, name := range []string{"a", "b", "c"} {
+		targets = append(targets, Target{Name: name, URL: ts.URL, Metrics: []Metric{{Selector: "up"}}})
+	}
+	src, err := NewSource(&Config{Targets: targets})
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	start := time.Now()
+	sensors, err := src.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if d := time.Since(start); d >= 3*delay {
+		t.Errorf("Read took %v, want the targets scraped concurrently", d)
+	}
+	for i, sens := range sensors {
+		if sens.Name != targets[i].Name || len(sens.Readings) != 1 {
+			t.Errorf("sensor %d = %+v, want %s in order", i, sens, targets[i].Name)
+		}
+	}
+}
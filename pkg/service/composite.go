@@ -0,0 +1,609 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SourceSeparator separates the source name from the sensor ID
+// of the source in the sensor IDs of a Composite
+const SourceSeparator = "/"
+
+// DefaultResubscribeInterval time before a Composite subscribes to a
+// failed source again
+const DefaultResubscribeInterval = 5 * time.Second
+
+// ErrNoSources the composite has no sources to serve from
+var ErrNoSources = errors.New("no hardware sources")
+
+// SourceHealth state of a source of a Composite as of its last call
+type SourceHealth struct {
+	Name string
+	// Err of the last call, nil when it succeeded
+	Err error
+	// LastOK time of the last successful call, zero if none yet
+	LastOK time.Time
+	// ProtocolVersion the source speaks, 0 when unknown
+	ProtocolVersion int
+}
+
+type source struct {
+	name   string
+	hw     HardwareService
+	err    error
+	lastOK time.Time
+}
+
+// Composite merges several HardwareServices, e.g. the local HWiNFO and
+// remote machines, into one. Sensor IDs of a named source are prefixed
+// with its name and SourceSeparator, the IDs of the default source,
+// added with an empty name, are left as is so tiles configured before
+// more sources were added keep working. A failing source is left out
+// of Sensors while the others are still served.
+type Composite struct {
+	// SourceTimeout bounds each source's part of a call, sources are
+	// asked concurrently so a hung one delays a call by this at most
+	SourceTimeout time.Duration
+	// ResubscribeInterval time before a source whose subscription
+	// failed is subscribed to again
+	ResubscribeInterval time.Duration
+
+	mu      sync.RWMutex
+	sources []*source
+}
+
+// NewComposite creates an empty Composite
+func NewComposite() *Composite {
+	return &Composite{SourceTimeout: DefaultTimeout, ResubscribeInterval: DefaultResubscribeInterval}
+}
+
+// Add adds hw as source name, replacing a source of the same name
+func (c *Composite) Add(name string, hw HardwareService) error {
+	if i := strings.IndexAny(name, SourceSeparator+SchemeSeparator); i >= 0 {
+		return fmt.Errorf("source name %q contains %q", name, name[i])
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, s := range c.sources {
+		if s.name == name {
+			s.hw, s.err = hw, nil
+			return nil
+		}
+	}
+	c.sources = append(c.sources, &source{name: name, hw: hw})
+	return nil
+}
+
+// Remove removes source name
+func (c *Composite) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, s := range c.sources {
+		if s.name == name {
+			c.sources = append(c.sources[:i], c.sources[i+1:]...)
+			return
+		}
+	}
+}
+
+// Health reports the state of every source in the order added
+func (c *Composite) Health() []SourceHealth {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	health := make([]SourceHealth, 0, len(c.sources))
+	for _, s := range c.sources {
+		h := SourceHealth{Name: s.name, Err: s.err, LastOK: s.lastOK}
+		if v, ok := s.hw.(Versioned); ok {
+			h.ProtocolVersion = v.ProtocolVersion()
+		}
+		health = append(health, h)
+	}
+	return health
+}
+
+// record the outcome of a call to s, logging when s goes down or recovers.
+// Calls given up by the caller say nothing about s.
+func (c *Composite) record(ctx context.Context, s *source, err error) {
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case err != nil && s.err == nil:
+		log.Printf("hardware source %q failed: %v\n", s.name, err)
+	case err == nil && s.err != nil:
+		log.Printf("hardware source %q recovered\n", s.name)
+	}
+	s.err = err
+	if err == nil {
+		s.lastOK = time.Now()
+	}
+}
+
+func (c *Composite) snapshot() []*source {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]*source(nil), c.sources...)
+}
+
+// sourceContext ctx bounded by SourceTimeout for a call to one source
+func (c *Composite) sourceContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.SourceTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.SourceTimeout)
+}
+
+// fanOut calls f for every source concurrently, each with its own
+// deadline of SourceTimeout, and waits for all of them
+func (c *Composite) fanOut(ctx context.Context, sources []*source, f func(ctx context.Context, i int, s *source)) {
+	var wg sync.WaitGroup
+	for i, s := range sources {
+		wg.Add(1)
+		go func(i int, s *source) {
+			defer wg.Done()
+			sctx, cancel := c.sourceContext(ctx)
+			defer cancel()
+			f(sctx, i, s)
+		}(i, s)
+	}
+	wg.Wait()
+}
+
+// route finds the source of a composite sensor ID and the ID within it
+func (c *Composite) route(id string) (*source, string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if name, sid, ok := strings.Cut(id, SourceSeparator); ok {
+		for _, s := range c.sources {
+			if s.name != "" && s.name == name {
+				return s, sid, nil
+			}
+		}
+	}
+	for _, s := range c.sources {
+		if s.name == "" {
+			return s, id, nil
+		}
+	}
+	return nil, "", fmt.Errorf("%w: no hardware source for sensor id %s", ErrSensorNotFound, id)
+}
+
+// PollTime implements HardwareService, the latest poll time of all
+// sources, fails only when every source failed
+func (c *Composite) PollTime(ctx context.Context) (uint64, error) {
+	sources := c.snapshot()
+	if len(sources) == 0 {
+		return 0, ErrNoSources
+	}
+	times := make([]uint64, len(sources))
+	errs := make([]error, len(sources))
+	c.fanOut(ctx, sources, func(sctx context.Context, i int, s *source) {
+		times[i], errs[i] = s.hw.PollTime(sctx)
+		c.record(ctx, s, errs[i])
+	})
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	var latest uint64
+	var lastErr error
+	ok := false
+	for i := range sources {
+		if errs[i] != nil {
+			lastErr = errs[i]
+			continue
+		}
+		ok = true
+		if times[i] > latest {
+			latest = times[i]
+		}
+	}
+	if !ok {
+		return 0, lastErr
+	}
+	return latest, nil
+}
+
+// Sensors implements HardwareService, the sensors of all sources
+// in the order added, fails only when every source failed
+func (c *Composite) Sensors(ctx context.Context) ([]Sensor, error) {
+	sources := c.snapshot()
+	if len(sources) == 0 {
+		return nil, ErrNoSources
+	}
+	perSource := make([][]Sensor, len(sources))
+	errs := make([]error, len(sources))
+	c.fanOut(ctx, sources, func(sctx context.Context, i int, s *source) {
+		perSource[i], errs[i] = s.hw.Sensors(sctx)
+		c.record(ctx, s, errs[i])
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var sensors []Sensor
+	var lastErr error
+	ok := false
+	for i, s := range sources {
+		if errs[i] != nil {
+			lastErr = fmt.Errorf("source %q: %w", s.name, errs[i])
+			continue
+		}
+		ok = true
+		for _, sens := range perSource[i] {
+			if s.name != "" {
+				sens = sourceSensor{Sensor: sens, source: s.name}
+			}
+			sensors = append(sensors, sens)
+		}
+	}
+	if !ok {
+		return nil, lastErr
+	}
+	return sensors, nil
+}
+
+// ReadingsForSensorID implements HardwareService
+func (c *Composite) ReadingsForSensorID(ctx context.Context, id string) ([]Reading, error) {
+	s, sid, err := c.route(id)
+	if err != nil {
+		return nil, err
+	}
+	readings, err := s.hw.ReadingsForSensorID(ctx, sid)
+	// an unknown sensor does not make the source unhealthy
+	if err == nil {
+		c.record(ctx, s, nil)
+	}
+	return readings, err
+}
+
+// ReadingsByKeys implements HardwareService, asking every source once
+// for its keys. Keys of a failing source get its error, the call fails
+// only when every source asked failed.
+func (c *Composite) ReadingsByKeys(ctx context.Context, keys []ReadingKey) ([]ReadingResult, error) {
+	results := make([]ReadingResult, len(keys))
+	type batch struct {
+		s    *source
+		keys []ReadingKey
+		// index of each key in results
+		idx []int
+	}
+	var batches []*batch
+	bySource := make(map[*source]*batch)
+	for i, k := range keys {
+		results[i].Key = k
+		s, sid, err := c.route(k.SensorID)
+		if err != nil {
+			results[i].Err = fmt.Errorf("%w: %v", ErrReadingNotFound, err)
+			continue
+		}
+		b, ok := bySource[s]
+		if !ok {
+			b = &batch{s: s}
+			bySource[s] = b
+			batches = append(batches, b)
+		}
+		b.keys = append(b.keys, ReadingKey{SensorID: sid, ReadingID: k.ReadingID})
+		b.idx = append(b.idx, i)
+	}
+
+	sources := make([]*source, len(batches))
+	for i, b := range batches {
+		sources[i] = b.s
+	}
+	res := make([][]ReadingResult, len(batches))
+	errs := make([]error, len(batches))
+	c.fanOut(ctx, sources, func(sctx context.Context, i int, s *source) {
+		res[i], errs[i] = s.hw.ReadingsByKeys(sctx, batches[i].keys)
+		c.record(ctx, s, errs[i])
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	ok := len(batches) == 0
+	for bi, b := range batches {
+		res, err := res[bi], errs[bi]
+		if err == nil && len(res) != len(b.keys) {
+			err = fmt.Errorf("got %d results for %d keys", len(res), len(b.keys))
+		}
+		if err != nil {
+			lastErr = fmt.Errorf("source %q: %w", b.s.name, err)
+			for _, i := range b.idx {
+				results[i].Err = lastErr
+			}
+			continue
+		}
+		ok = true
+		for j, i := range b.idx {
+			results[i].Reading, results[i].Err = res[j].Reading, res[j].Err
+		}
+	}
+	if !ok {
+		return nil, lastErr
+	}
+	return results, nil
+}
+
+// CompositeBackend backend name of a Composite's status
+const CompositeBackend = "composite"
+
+// Status implements HardwareService, summing up the sources. The
+// composite is active while any source is, otherwise it takes the state
+// of the default source, or the first source without a default.
+func (c *Composite) Status(ctx context.Context) (Status, error) {
+	sources := c.snapshot()
+	if len(sources) == 0 {
+		return Status{}, ErrNoSources
+	}
+	statuses := make([]Status, len(sources))
+	errs := make([]error, len(sources))
+	c.fanOut(ctx, sources, func(sctx context.Context, i int, s *source) {
+		statuses[i], errs[i] = c.sourceStatus(ctx, sctx, s)
+	})
+	if err := ctx.Err(); err != nil {
+		return Status{}, err
+	}
+	st := Status{Backend: CompositeBackend}
+	var fallback *Status
+	for i, s := range sources {
+		ss, err := statuses[i], errs[i]
+		if fallback == nil || s.name == "" {
+			fallback = &ss
+		}
+		if ss.LastError != "" && st.LastError == "" {
+			st.LastError = fmt.Sprintf("source %q: %s", s.name, ss.LastError)
+		}
+		if err != nil {
+			continue
+		}
+		if ss.State == StateActive {
+			st.State = StateActive
+		}
+		if ss.PollTime > st.PollTime {
+			st.PollTime = ss.PollTime
+		}
+		if ss.LastRead.After(st.LastRead) {
+			st.LastRead = ss.LastRead
+		}
+		st.Sensors += ss.Sensors
+		st.Readings += ss.Readings
+	}
+	if st.State != StateActive {
+		st.State = fallback.State
+	}
+	return st, nil
+}
+
+// sourceStatus the status of s asked with sctx, a source whose status
+// cannot be determined is reported as not found
+func (c *Composite) sourceStatus(ctx, sctx context.Context, s *source) (Status, error) {
+	st, err := s.hw.Status(sctx)
+	c.record(ctx, s, err)
+	if err != nil {
+		return Status{State: StateNotFound, LastError: err.Error()}, err
+	}
+	return st, nil
+}
+
+// SourceOf name of the source serving sensor id
+func (c *Composite) SourceOf(id string) (string, error) {
+	s, _, err := c.route(id)
+	if err != nil {
+		return "", err
+	}
+	return s.name, nil
+}
+
+// SourceStatus status of source name, a source whose status cannot
+// be determined is reported as not found along with the error
+func (c *Composite) SourceStatus(ctx context.Context, name string) (Status, error) {
+	for _, s := range c.snapshot() {
+		if s.name == name {
+			sctx, cancel := c.sourceContext(ctx)
+			defer cancel()
+			return c.sourceStatus(ctx, sctx, s)
+		}
+	}
+	return Status{}, fmt.Errorf("no hardware source %q", name)
+}
+
+// History implements Historian, asking the source of key
+func (c *Composite) History(ctx context.Context, key ReadingKey, since time.Time, maxPoints int) ([]Sample, error) {
+	s, sid, err := c.route(key.SensorID)
+	if err != nil {
+		return nil, err
+	}
+	return History(ctx, s.hw, ReadingKey{SensorID: sid, ReadingID: key.ReadingID}, since, maxPoints)
+}
+
+// FindReadings implements Finder, searching all sources, results are
+// in the order the sources were added. Names are matched as the source
+// reports them, without the source prefix. Fails only when every
+// source asked failed.
+func (c *Composite) FindReadings(ctx context.Context, q Query) ([]Found, error) {
+	if _, err := q.matcher(); err != nil {
+		return nil, err
+	}
+	sources := c.snapshot()
+	if len(sources) == 0 {
+		return nil, ErrNoSources
+	}
+	res := make([][]Found, len(sources))
+	errs := make([]error, len(sources))
+	c.fanOut(ctx, sources, func(sctx context.Context, i int, s *source) {
+		res[i], errs[i] = FindReadings(sctx, s.hw, q)
+		c.record(ctx, s, errs[i])
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var found []Found
+	var lastErr error
+	ok := false
+	for i, s := range sources {
+		if errs[i] != nil {
+			lastErr = fmt.Errorf("source %q: %w", s.name, errs[i])
+			continue
+		}
+		ok = true
+		for _, f := range res[i] {
+			if s.name != "" {
+				f.Sensor = sourceSensor{Sensor: f.Sensor, source: s.name}
+			}
+			found = append(found, f)
+		}
+	}
+	if !ok {
+		return nil, lastErr
+	}
+	if q.Limit > 0 && len(found) > q.Limit {
+		found = found[:q.Limit]
+	}
+	return found, nil
+}
+
+// Subscribe implements Subscriber, subscribing to every source holding
+// one of keys. Updates of each source are delivered as they arrive,
+// keys without a source are reported missing in the first update.
+// While a source fails its keys are reported missing and it is
+// subscribed to again every ResubscribeInterval, the others keep
+// delivering. The subscription only ends with ctx.
+func (c *Composite) Subscribe(ctx context.Context, keys []ReadingKey, minInterval time.Duration) (<-chan Update, error) {
+	type sub struct {
+		s    *source
+		keys []ReadingKey
+		// composite keys by key within the source
+		orig map[ReadingKey]ReadingKey
+	}
+	var subs []*sub
+	bySource := make(map[*source]*sub)
+	var unrouted []ReadingKey
+	for _, k := range keys {
+		s, sid, err := c.route(k.SensorID)
+		if err != nil {
+			unrouted = append(unrouted, k)
+			continue
+		}
+		sb, ok := bySource[s]
+		if !ok {
+			sb = &sub{s: s, orig: make(map[ReadingKey]ReadingKey)}
+			bySource[s] = sb
+			subs = append(subs, sb)
+		}
+		sk := ReadingKey{SensorID: sid, ReadingID: k.ReadingID}
+		sb.keys = append(sb.keys, sk)
+		sb.orig[sk] = k
+	}
+
+	out := make(chan Update)
+	var wg sync.WaitGroup
+	send := func(u Update) bool {
+		select {
+		case out <- u:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	if len(unrouted) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			send(Update{Readings: map[ReadingKey]Reading{}, Missing: unrouted})
+		}()
+	}
+	for _, sb := range subs {
+		wg.Add(1)
+		go func(sb *sub) {
+			defer wg.Done()
+			c.forward(ctx, sb.s, sb.keys, sb.orig, minInterval, send)
+		}(sb)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out, nil
+}
+
+// forward delivers the updates of a subscription to keys of s under
+// their composite keys orig until ctx is done, resubscribing while s fails
+func (c *Composite) forward(ctx context.Context, s *source, keys []ReadingKey, orig map[ReadingKey]ReadingKey, minInterval time.Duration, send func(Update) bool) {
+	missing := make([]ReadingKey, 0, len(keys))
+	for _, k := range keys {
+		missing = append(missing, orig[k])
+	}
+	for {
+		ch, err := Subscribe(ctx, s.hw, keys, minInterval)
+		if err == nil {
+			for u := range ch {
+				if u.Err != nil {
+					err = u.Err
+					break
+				}
+				c.record(ctx, s, nil)
+				cu := Update{PollTime: u.PollTime, Readings: make(map[ReadingKey]Reading, len(u.Readings))}
+				for k, r := range u.Readings {
+					cu.Readings[orig[k]] = r
+				}
+				for _, k := range u.Missing {
+					cu.Missing = append(cu.Missing, orig[k])
+				}
+				if !send(cu) {
+					return
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("subscription ended")
+		}
+		c.record(ctx, s, fmt.Errorf("source %q: %w", s.name, err))
+		if !send(Update{Readings: map[ReadingKey]Reading{}, Missing: missing}) {
+			return
+		}
+		interval := c.ResubscribeInterval
+		if interval <= 0 {
+			interval = DefaultResubscribeInterval
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sourceSensor a sensor of a named source
+type sourceSensor struct {
+	Sensor
+	source string
+}
+
+func (s sourceSensor) ID() string {
+	return s.source + SourceSeparator + s.Sensor.ID()
+}
+
+// Name includes the source so sensors of different machines
+// can be told apart
+func (s sourceSensor) Name() string {
+	return s.source + ": " + s.Sensor.Name()
+}
+
+func (s sourceSensor) NameUser() string {
+	return s.source + ": " + s.Sensor.NameUser()
+}
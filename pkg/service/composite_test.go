@@ -0,0 +1,236 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type fakeSensor struct{ id, name string }
+
+func (s fakeSensor) ID() string       { return s.id }
+func (s fakeSensor) Name() string     { return s.name }
+func (s fakeSensor) NameUser() string { return s.name }
+
+type fakeService struct {
+	sensors []Sensor
+	err     error
+}
+
+func (f *fakeService) PollTime(context.Context) (uint64, error) { return 1, f.err }
+
+func (f *fakeService) Sensors(context.Context) ([]Sensor, error) { return f.sensors, f.err }
+
+func (f *fakeService) ReadingsForSensorID(_ context.Context, id string) ([]Reading, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, s := range f.sensors {
+		if s.ID() == id {
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: readings for sensor id %s do not exist", ErrSensorNotFound, id)
+}
+
+func (f *fakeService) ReadingsByKeys(ctx context.Context, keys []ReadingKey) ([]ReadingResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return LookupReadings(keys, func(id string) ([]Reading, error) { return f.ReadingsForSensorID(ctx, id) }), nil
+}
+
+func (f *fakeService) Status(context.Context) (Status, error) {
+	if f.err != nil {
+		return Status{}, f.err
+	}
+	return Status{Backend: "fake", State: StateActive, Sensors: len(f.sensors)}, nil
+}
+
+func TestComposite(t *testing.T) {
+	ctx := context.Background()
+	local := &fakeService{sensors: []Sensor{fakeSensor{"100", "CPU"}}}
+	lab := &fakeService{sensors: []Sensor{fakeSensor{"hwmon:k10temp/hwmon0", "k10temp"}}}
+	down := &fakeService{err: errors.New("connection refused")}
+
+	c := NewComposite()
+	for name, hw := range map[string]HardwareService{"": local, "lab": lab} {
+		if err := c.Add(name, hw); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Add("down", down); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add("a/b", lab); err == nil {
+		t.Error("expected error for name with separator")
+	}
+
+	sensors, err := c.Sensors(ctx)
+	if err != nil {
+		t.Fatalf("Sensors: %v", err)
+	}
+	ids := make(map[string]string)
+	for _, s := range sensors {
+		ids[s.ID()] = s.Name()
+	}
+	want := map[string]string{"100": "CPU", "lab/hwmon:k10temp/hwmon0": "lab: k10temp"}
+	if len(ids) != len(want) {
+		t.Fatalf("sensors = %v, want %v", ids, want)
+	}
+	for id, name := range want {
+		if ids[id] != name {
+			t.Errorf("sensor %s = %q, want %q", id, ids[id], name)
+		}
+	}
+
+	for _, id := range []string{"100", "lab/hwmon:k10temp/hwmon0"} {
+		if _, err := c.ReadingsForSensorID(ctx, id); err != nil {
+			t.Errorf("ReadingsForSensorID(%s): %v", id, err)
+		}
+	}
+	if _, err := c.ReadingsForSensorID(ctx, "down/1"); err == nil {
+		t.Error("expected error from the source that is down")
+	}
+
+	for _, h := range c.Health() {
+		if (h.Err != nil) != (h.Name == "down") {
+			t.Errorf("health %q = %v", h.Name, h.Err)
+		}
+	}
+
+	st, err := c.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if st.State != StateActive || st.Sensors != 2 || st.LastError == "" {
+		t.Errorf("status = %+v, want active with 2 sensors and the error of source down", st)
+	}
+	if name, err := c.SourceOf("down/1"); err != nil || name != "down" {
+		t.Errorf("SourceOf = %q, %v", name, err)
+	}
+	if st, err := c.SourceStatus(ctx, "down"); err == nil || st.State != StateNotFound {
+		t.Errorf("SourceStatus down = %+v, %v", st, err)
+	}
+
+	c.Remove("")
+	c.Remove("lab")
+	if _, err := c.Sensors(ctx); err == nil {
+		t.Error("expected error when every source failed")
+	}
+}
+
+func TestCompositeRoute(t *testing.T) {
+	// backend IDs hold slashes, the default one even starts with the
+	// name of another source
+	local := &fakeService{sensors: []Sensor{fakeSensor{"100", "CPU"}, fakeSensor{"lhm:lab/amdcpu/0", "Ryzen"}}}
+	lab := &fakeService{sensors: []Sensor{fakeSensor{"hwmon:nct6798/nct6775.656", "nct6798"}}}
+	c := NewComposite()
+	if err := c.Add("", local); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add("lab", lab); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add("lhm:lab", lab); err == nil {
+		t.Error("expected error for name with scheme separator")
+	}
+
+	tests := []struct {
+		id     string
+		source string
+		sid    string
+	}{
+		{"100", "", "100"},
+		{"lhm:lab/amdcpu/0", "", "lhm:lab/amdcpu/0"},
+		{"lab/hwmon:nct6798/nct6775.656", "lab", "hwmon:nct6798/nct6775.656"},
+		{"nas/1", "", "nas/1"},
+	}
+	for _, tt := range tests {
+		s, sid, err := c.route(tt.id)
+		if err != nil {
+			t.Errorf("route(%s): %v", tt.id, err)
+			continue
+		}
+		if s.name != tt.source || sid != tt.sid {
+			t.Errorf("route(%s) = %q %q, want %q %q", tt.id, s.name, sid, tt.source, tt.sid)
+		}
+	}
+	for _, id := range []string{"lhm:lab/amdcpu/0", "lab/hwmon:nct6798/nct6775.656"} {
+		if _, err := c.ReadingsForSensorID(context.Background(), id); err != nil {
+			t.Errorf("ReadingsForSensorID(%s): %v", id, err)
+		}
+	}
+}
+
+func TestCompositeReadingsByKeys(t *testing.T) {
+	ctx := context.Background()
+	c := NewComposite()
+	if err := c.Add("", &pollingService{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add("down", &fakeService{err: errors.New("connection refused")}); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []ReadingKey{{"100", 2}, {"down/1", 1}, {"100", 1}, {"100", 3}, {"200", 1}}
+	results, err := c.ReadingsByKeys(ctx, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(keys) {
+		t.Fatalf("got %d results, want %d", len(results), len(keys))
+	}
+	for i, res := range results {
+		if res.Key != keys[i] {
+			t.Errorf("result %d key = %v, want %v", i, res.Key, keys[i])
+		}
+	}
+	for _, i := range []int{0, 2} {
+		if results[i].Err != nil || results[i].Reading.ID() != keys[i].ReadingID {
+			t.Errorf("result %v = %v, %v", keys[i], results[i].Reading, results[i].Err)
+		}
+	}
+	for _, i := range []int{3, 4} {
+		if !errors.Is(results[i].Err, ErrReadingNotFound) {
+			t.Errorf("result %v err = %v, want ErrReadingNotFound", keys[i], results[i].Err)
+		}
+	}
+	if err := results[1].Err; err == nil || errors.Is(err, ErrReadingNotFound) {
+		t.Errorf("result of failing source err = %v", err)
+	}
+
+	if _, err := c.ReadingsByKeys(ctx, []ReadingKey{{"down/1", 1}}); err == nil {
+		t.Error("expected error when every source asked failed")
+	}
+}
+
+func TestCompositeSourceTimeout(t *testing.T) {
+	c := NewComposite()
+	c.SourceTimeout = 100 * time.Millisecond
+	if err := c.Add("", &fakeService{}); err != nil {
+		t.Fatal(err)
+	}
+	// sources are asked side by side, hung ones cost one timeout together
+	for _, name := range []string{"hung1", "hung2", "hung3"} {
+		if err := c.Add(name, &hungService{cancelled: make(chan struct{})}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Now()
+	pt, err := c.PollTime(context.Background())
+	if err != nil || pt != 1 {
+		t.Fatalf("PollTime = %d, %v", pt, err)
+	}
+	if d := time.Since(start); d >= 3*c.SourceTimeout {
+		t.Errorf("PollTime took %v, sources not asked concurrently", d)
+	}
+	for _, h := range c.Health() {
+		if h.Name != "" && !errors.Is(h.Err, context.DeadlineExceeded) {
+			t.Errorf("source %q err = %v, want deadline exceeded", h.Name, h.Err)
+		}
+	}
+}
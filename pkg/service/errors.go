@@ -0,0 +1,97 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrSensorNotFound the service has no sensor of the requested ID
+	ErrSensorNotFound = errors.New("sensor not found")
+	// ErrUnavailable the source cannot be read right now, e.g.
+	// HWiNFO is not running or a remote machine is unreachable
+	ErrUnavailable = errors.New("hardware source unavailable")
+	// ErrLayoutMismatch the source is running but its data cannot be
+	// decoded, e.g. an unsupported HWiNFO shared memory layout
+	ErrLayoutMismatch = errors.New("hardware source layout mismatch")
+)
+
+// errorDomain of the ErrorInfo detail of status errors
+const errorDomain = "hwinfo-streamdeck"
+
+// statusCodes errors sent as gRPC status codes, the reason tells
+// errors of the same code apart. Without a reason, e.g. from a server
+// predating it or the transport, the first error of the code is used.
+var statusCodes = []struct {
+	err    error
+	code   codes.Code
+	reason string
+}{
+	{ErrSensorNotFound, codes.NotFound, "SENSOR_NOT_FOUND"},
+	{ErrReadingNotFound, codes.NotFound, "READING_NOT_FOUND"},
+	{ErrUnavailable, codes.Unavailable, "UNAVAILABLE"},
+	{ErrNoSources, codes.Unavailable, "NO_SOURCES"},
+	{ErrLayoutMismatch, codes.FailedPrecondition, "LAYOUT_MISMATCH"},
+	{ErrInvalidQuery, codes.InvalidArgument, "INVALID_QUERY"},
+}
+
+// statusError err of GRPCServer.Impl as a gRPC status error
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(interface{ GRPCStatus() *status.Status }); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	for _, sc := range statusCodes {
+		if !errors.Is(err, sc.err) {
+			continue
+		}
+		st := status.New(sc.code, err.Error())
+		if d, derr := st.WithDetails(&errdetails.ErrorInfo{Reason: sc.reason, Domain: errorDomain}); derr == nil {
+			st = d
+		}
+		return st.Err()
+	}
+	return err
+}
+
+// clientError turns a status error received by GRPCClient back into
+// the error it was sent for, status.Code still reports its code
+func clientError(err error) error {
+	st, ok := status.FromError(err)
+	if err == nil || !ok {
+		return err
+	}
+	var reason string
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetDomain() == errorDomain {
+			reason = info.GetReason()
+		}
+	}
+	for _, sc := range statusCodes {
+		if sc.code == st.Code() && (reason == "" || reason == sc.reason) {
+			return &rpcError{st: st, err: sc.err}
+		}
+	}
+	return err
+}
+
+// rpcError a status error matching the error it was sent for
+type rpcError struct {
+	st  *status.Status
+	err error
+}
+
+func (e *rpcError) Error() string { return e.st.Err().Error() }
+
+func (e *rpcError) Unwrap() error { return e.err }
+
+func (e *rpcError) GRPCStatus() *status.Status { return e.st }
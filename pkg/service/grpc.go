@@ -3,31 +3,63 @@ package hwsensorsservice
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	"github.com/shayne/hwinfo-streamdeck/pkg/service/proto"
 )
 
+// DefaultTimeout of a call of GRPCClient, long enough for a busy
+// hwinfo-plugin and short enough not to stall the tiles for long
+const DefaultTimeout = 5 * time.Second
+
 // GRPCClient is an implementation of KV that talks over RPC.
 type GRPCClient struct {
 	Client proto.HWServiceClient
+	// Timeout of calls whose context has no deadline, none when zero.
+	// Subscriptions are not limited.
+	Timeout time.Duration
+	// Version protocol version negotiated with the plugin,
+	// 0 for standalone servers which are not negotiated with
+	Version int
+}
+
+// ProtocolVersion implements Versioned
+func (c *GRPCClient) ProtocolVersion() int {
+	return c.Version
+}
+
+// withTimeout applies c.Timeout unless ctx has a deadline already
+func (c *GRPCClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || c.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.Timeout)
 }
 
 // PollTime rpc call
-func (c *GRPCClient) PollTime() (uint64, error) {
-	resp, err := c.Client.PollTime(context.Background(), &empty.Empty{})
+func (c *GRPCClient) PollTime(ctx context.Context) (uint64, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := c.Client.PollTime(ctx, &empty.Empty{})
 	if err != nil {
-		return 0, err
+		return 0, clientError(err)
 	}
 	return resp.GetPollTime(), nil
 }
 
 // Sensors implementation
-func (c *GRPCClient) Sensors() ([]Sensor, error) {
-	stream, err := c.Client.Sensors(context.Background(), &empty.Empty{})
+func (c *GRPCClient) Sensors(ctx context.Context) ([]Sensor, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	stream, err := c.Client.Sensors(ctx, &empty.Empty{})
 	if err != nil {
-		return nil, err
+		return nil, clientError(err)
 	}
 
 	var sensors []Sensor
@@ -37,7 +69,7 @@ func (c *GRPCClient) Sensors() ([]Sensor, error) {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, clientError(err)
 		}
 		sensors = append(sensors, &sensor{s})
 	}
@@ -46,10 +78,12 @@ func (c *GRPCClient) Sensors() ([]Sensor, error) {
 }
 
 // ReadingsForSensorID implementation
-func (c *GRPCClient) ReadingsForSensorID(id string) ([]Reading, error) {
-	stream, err := c.Client.ReadingsForSensorID(context.Background(), &proto.SensorIDRequest{Id: id})
+func (c *GRPCClient) ReadingsForSensorID(ctx context.Context, id string) ([]Reading, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	stream, err := c.Client.ReadingsForSensorID(ctx, &proto.SensorIDRequest{Id: id})
 	if err != nil {
-		return nil, err
+		return nil, clientError(err)
 	}
 
 	var readings []Reading
@@ -59,7 +93,7 @@ func (c *GRPCClient) ReadingsForSensorID(id string) ([]Reading, error) {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, clientError(err)
 		}
 		readings = append(readings, &reading{r})
 	}
@@ -67,6 +101,182 @@ func (c *GRPCClient) ReadingsForSensorID(id string) ([]Reading, error) {
 	return readings, nil
 }
 
+// ReadingsByKeys rpc call
+func (c *GRPCClient) ReadingsByKeys(ctx context.Context, keys []ReadingKey) ([]ReadingResult, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req := &proto.ReadingsByKeysRequest{}
+	for _, k := range keys {
+		req.Keys = append(req.Keys, &proto.ReadingKey{SensorID: k.SensorID, ReadingID: k.ReadingID})
+	}
+	resp, err := c.Client.ReadingsByKeys(ctx, req)
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	results := make([]ReadingResult, 0, len(resp.GetResults()))
+	for _, r := range resp.GetResults() {
+		res := ReadingResult{Key: ReadingKey{SensorID: r.GetKey().GetSensorID(), ReadingID: r.GetKey().GetReadingID()}}
+		if msg := r.GetError(); msg != "" {
+			res.Err = &resultError{msg: msg, notFound: r.GetNotFound()}
+		} else {
+			res.Reading = &reading{r.GetReading()}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// Status rpc call, as far as PollTime and Sensors tell for a
+// server that predates Status
+func (c *GRPCClient) Status(ctx context.Context) (Status, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := c.Client.Status(ctx, &empty.Empty{})
+	if status.Code(err) == codes.Unimplemented {
+		return (&legacyClient{c: c}).Status(ctx)
+	}
+	if err != nil {
+		return Status{}, clientError(err)
+	}
+	st := Status{
+		Backend:   resp.GetBackend(),
+		State:     State(resp.GetState()),
+		Version:   int(resp.GetVersion()),
+		Revision:  int(resp.GetRevision()),
+		PollTime:  resp.GetPollTime(),
+		LastError: resp.GetLastError(),
+		Sensors:   int(resp.GetSensors()),
+		Readings:  int(resp.GetReadings()),
+	}
+	// ages instead of times, the clocks of remote machines may differ
+	if age := resp.GetLastReadAgeMs(); age >= 0 {
+		st.LastRead = time.Now().Add(-time.Duration(age) * time.Millisecond)
+	}
+	return st, nil
+}
+
+// History implements Historian, ErrNoHistory when the server
+// keeps none or predates History
+func (c *GRPCClient) History(ctx context.Context, key ReadingKey, since time.Time, maxPoints int) ([]Sample, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	req := &proto.HistoryRequest{
+		Key:       &proto.ReadingKey{SensorID: key.SensorID, ReadingID: key.ReadingID},
+		MaxPoints: int32(maxPoints),
+	}
+	if !since.IsZero() {
+		req.SinceUnixMs = since.UnixMilli()
+	}
+	resp, err := c.Client.History(ctx, req)
+	if status.Code(err) == codes.Unimplemented {
+		return nil, fmt.Errorf("%w: %v", ErrNoHistory, err)
+	}
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	samples := make([]Sample, 0, len(resp.GetSamples()))
+	for _, s := range resp.GetSamples() {
+		samples = append(samples, Sample{Time: time.UnixMilli(s.GetUnixMs()), Value: s.GetValue()})
+	}
+	return samples, nil
+}
+
+// FindReadings implements Finder, walking the sensors of a server
+// that predates FindReadings
+func (c *GRPCClient) FindReadings(ctx context.Context, q Query) ([]Found, error) {
+	if _, err := q.matcher(); err != nil {
+		return nil, clientError(err)
+	}
+	req := &proto.FindReadingsRequest{
+		Unit:    q.Unit,
+		Text:    q.Text,
+		Pattern: q.Pattern,
+		Limit:   int32(q.Limit),
+	}
+	for _, t := range q.Types {
+		req.Types = append(req.Types, int32(t))
+	}
+	if q.Min != nil {
+		req.HasMin, req.Min = true, *q.Min
+	}
+	if q.Max != nil {
+		req.HasMax, req.Max = true, *q.Max
+	}
+	tctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	resp, err := c.Client.FindReadings(tctx, req)
+	if status.Code(err) == codes.Unimplemented {
+		return WalkFindReadings(ctx, c, q)
+	}
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	found := make([]Found, 0, len(resp.GetReadings()))
+	for _, f := range resp.GetReadings() {
+		found = append(found, Found{Sensor: &sensor{f.GetSensor()}, Reading: &reading{f.GetReading()}})
+	}
+	return found, nil
+}
+
+// resultError a per-key error of ReadingsByKeys received over gRPC
+type resultError struct {
+	msg      string
+	notFound bool
+}
+
+func (e *resultError) Error() string { return e.msg }
+
+func (e *resultError) Is(target error) bool {
+	return e.notFound && target == ErrReadingNotFound
+}
+
+// Subscribe implements Subscriber, the last update carries the error
+// that ended the stream unless ctx was cancelled
+func (c *GRPCClient) Subscribe(ctx context.Context, keys []ReadingKey, minInterval time.Duration) (<-chan Update, error) {
+	req := &proto.SubscribeRequest{MinIntervalMs: minInterval.Milliseconds()}
+	for _, k := range keys {
+		req.Keys = append(req.Keys, &proto.ReadingKey{SensorID: k.SensorID, ReadingID: k.ReadingID})
+	}
+	stream, err := c.Client.Subscribe(ctx, req)
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	ch := make(chan Update)
+	go func() {
+		defer close(ch)
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+					select {
+					case ch <- Update{Err: clientError(err)}:
+					case <-ctx.Done():
+					}
+				}
+				return
+			}
+			u := Update{PollTime: msg.GetPollTime(), Readings: make(map[ReadingKey]Reading, len(msg.GetReadings()))}
+			for _, r := range msg.GetReadings() {
+				u.Readings[ReadingKey{SensorID: r.GetSensorID(), ReadingID: r.GetReading().GetID()}] = &reading{r.GetReading()}
+			}
+			for _, k := range msg.GetMissing() {
+				u.Missing = append(u.Missing, ReadingKey{SensorID: k.GetSensorID(), ReadingID: k.GetReadingID()})
+			}
+			select {
+			case ch <- u:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
 // GRPCServer is the gRPC server that GRPCClient talks to.
 type GRPCServer struct {
 	// This is the real implementation
@@ -76,21 +286,22 @@ type GRPCServer struct {
 
 // PollTime gRPC wrapper
 func (s *GRPCServer) PollTime(ctx context.Context, _ *empty.Empty) (*proto.PollTimeReply, error) {
-	v, err := s.Impl.PollTime()
-	return &proto.PollTimeReply{PollTime: v}, err
+	v, err := s.Impl.PollTime(ctx)
+	return &proto.PollTimeReply{PollTime: v}, statusError(err)
 }
 
 // Sensors gRPC wrapper
 func (s *GRPCServer) Sensors(_ *empty.Empty, stream proto.HWService_SensorsServer) error {
-	sensors, err := s.Impl.Sensors()
+	sensors, err := s.Impl.Sensors(stream.Context())
 	if err != nil {
-		return err
+		return statusError(err)
 	}
 
 	for _, sensor := range sensors {
 		if err := stream.Send(&proto.Sensor{
-			ID:   sensor.ID(),
-			Name: sensor.Name(),
+			ID:       sensor.ID(),
+			Name:     sensor.Name(),
+			NameUser: sensor.NameUser(),
 		}); err != nil {
 			return err
 		}
@@ -101,26 +312,170 @@ func (s *GRPCServer) Sensors(_ *empty.Empty, stream proto.HWService_SensorsServe
 
 // ReadingsForSensorID gRPC wrapper
 func (s *GRPCServer) ReadingsForSensorID(req *proto.SensorIDRequest, stream proto.HWService_ReadingsForSensorIDServer) error {
-	readings, err := s.Impl.ReadingsForSensorID(req.GetId())
+	readings, err := s.Impl.ReadingsForSensorID(stream.Context(), req.GetId())
 	if err != nil {
-		return err
+		return statusError(err)
 	}
 
 	for _, reading := range readings {
-		if err := stream.Send(&proto.Reading{
-			ID:       reading.ID(),
-			TypeI:    reading.TypeI(),
-			Type:     reading.Type(),
-			Label:    reading.Label(),
-			Unit:     reading.Unit(),
-			Value:    reading.Value(),
-			ValueMin: reading.ValueMin(),
-			ValueMax: reading.ValueMax(),
-			ValueAvg: reading.ValueAvg(),
-		}); err != nil {
+		if err := stream.Send(readingProto(reading)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Subscribe gRPC wrapper, polls Impl unless it is a Subscriber
+func (s *GRPCServer) Subscribe(req *proto.SubscribeRequest, stream proto.HWService_SubscribeServer) error {
+	keys := make([]ReadingKey, 0, len(req.GetKeys()))
+	for _, k := range req.GetKeys() {
+		keys = append(keys, ReadingKey{SensorID: k.GetSensorID(), ReadingID: k.GetReadingID()})
+	}
+	interval := time.Duration(req.GetMinIntervalMs()) * time.Millisecond
+	ch, err := Subscribe(stream.Context(), s.Impl, keys, interval)
+	if err != nil {
+		return statusError(err)
+	}
+
+	for u := range ch {
+		if u.Err != nil {
+			return statusError(u.Err)
+		}
+		msg := &proto.ReadingUpdate{PollTime: u.PollTime}
+		for k, r := range u.Readings {
+			msg.Readings = append(msg.Readings, &proto.SensorReading{SensorID: k.SensorID, Reading: readingProto(r)})
+		}
+		for _, k := range u.Missing {
+			msg.Missing = append(msg.Missing, &proto.ReadingKey{SensorID: k.SensorID, ReadingID: k.ReadingID})
+		}
+		if err := stream.Send(msg); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// ReadingsByKeys gRPC wrapper
+func (s *GRPCServer) ReadingsByKeys(ctx context.Context, req *proto.ReadingsByKeysRequest) (*proto.ReadingsByKeysReply, error) {
+	keys := make([]ReadingKey, 0, len(req.GetKeys()))
+	for _, k := range req.GetKeys() {
+		keys = append(keys, ReadingKey{SensorID: k.GetSensorID(), ReadingID: k.GetReadingID()})
+	}
+	results, err := s.Impl.ReadingsByKeys(ctx, keys)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &proto.ReadingsByKeysReply{}
+	for _, res := range results {
+		r := &proto.ReadingResult{Key: &proto.ReadingKey{SensorID: res.Key.SensorID, ReadingID: res.Key.ReadingID}}
+		if res.Err != nil {
+			r.Error = res.Err.Error()
+			r.NotFound = errors.Is(res.Err, ErrReadingNotFound)
+		} else {
+			r.Reading = readingProto(res.Reading)
+		}
+		resp.Results = append(resp.Results, r)
+	}
+	return resp, nil
+}
+
+// Status gRPC wrapper
+func (s *GRPCServer) Status(ctx context.Context, _ *empty.Empty) (*proto.StatusReply, error) {
+	st, err := s.Impl.Status(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	age := int64(-1)
+	if !st.LastRead.IsZero() {
+		age = st.Age().Milliseconds()
+	}
+	return &proto.StatusReply{
+		Backend:       st.Backend,
+		State:         int32(st.State),
+		Version:       int32(st.Version),
+		Revision:      int32(st.Revision),
+		PollTime:      st.PollTime,
+		LastReadAgeMs: age,
+		LastError:     st.LastError,
+		Sensors:       int32(st.Sensors),
+		Readings:      int32(st.Readings),
+	}, nil
+}
+
+// History gRPC wrapper, Unimplemented when Impl keeps no history
+func (s *GRPCServer) History(ctx context.Context, req *proto.HistoryRequest) (*proto.HistoryReply, error) {
+	var since time.Time
+	if ms := req.GetSinceUnixMs(); ms != 0 {
+		since = time.UnixMilli(ms)
+	}
+	key := ReadingKey{SensorID: req.GetKey().GetSensorID(), ReadingID: req.GetKey().GetReadingID()}
+	samples, err := History(ctx, s.Impl, key, since, int(req.GetMaxPoints()))
+	if errors.Is(err, ErrNoHistory) {
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	}
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &proto.HistoryReply{Samples: make([]*proto.HistorySample, 0, len(samples))}
+	for _, sm := range samples {
+		resp.Samples = append(resp.Samples, &proto.HistorySample{UnixMs: sm.Time.UnixMilli(), Value: sm.Value})
+	}
+	return resp, nil
+}
+
+// FindReadings gRPC wrapper
+func (s *GRPCServer) FindReadings(ctx context.Context, req *proto.FindReadingsRequest) (*proto.FindReadingsReply, error) {
+	q := Query{
+		Unit:    req.GetUnit(),
+		Text:    req.GetText(),
+		Pattern: req.GetPattern(),
+		Limit:   int(req.GetLimit()),
+	}
+	for _, t := range req.GetTypes() {
+		q.Types = append(q.Types, ReadingType(t))
+	}
+	if req.GetHasMin() {
+		min := req.GetMin()
+		q.Min = &min
+	}
+	if req.GetHasMax() {
+		max := req.GetMax()
+		q.Max = &max
+	}
+	found, err := FindReadings(ctx, s.Impl, q)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &proto.FindReadingsReply{Readings: make([]*proto.FoundReading, 0, len(found))}
+	for _, f := range found {
+		resp.Readings = append(resp.Readings, &proto.FoundReading{
+			Sensor: &proto.Sensor{
+				ID:       f.Sensor.ID(),
+				Name:     f.Sensor.Name(),
+				NameUser: f.Sensor.NameUser(),
+			},
+			Reading: readingProto(f.Reading),
+		})
+	}
+	return resp, nil
+}
+
+func readingProto(r Reading) *proto.Reading {
+	return &proto.Reading{
+		ID:        r.ID(),
+		TypeI:     r.TypeI(),
+		Type:      r.Type(),
+		Label:     r.Label(),
+		LabelUser: r.LabelUser(),
+		Unit:      r.Unit(),
+		Value:     r.Value(),
+		ValueMin:  r.ValueMin(),
+		ValueMax:  r.ValueMax(),
+		ValueAvg:  r.ValueAvg(),
+	}
+}
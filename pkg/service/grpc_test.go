@@ -0,0 +1,183 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/shayne/hwinfo-streamdeck/pkg/service/proto"
+)
+
+// hungService never answers until the call is given up
+type hungService struct {
+	fakeService
+	cancelled chan struct{}
+}
+
+func (h *hungService) PollTime(ctx context.Context) (uint64, error) {
+	<-ctx.Done()
+	close(h.cancelled)
+	return 0, ctx.Err()
+}
+
+// serveTest serves impl in plain text until the test ends
+func serveTest(t *testing.T, impl proto.HWServiceServer) *grpc.ClientConn {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	proto.RegisterHWServiceServer(srv, impl)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestClientTimeout(t *testing.T) {
+	hw := &hungService{cancelled: make(chan struct{})}
+	conn := serveTest(t, &GRPCServer{Impl: hw})
+	c := &GRPCClient{Client: proto.NewHWServiceClient(conn), Timeout: 50 * time.Millisecond}
+
+	start := time.Now()
+	_, err := c.PollTime(context.Background())
+	if code := status.Code(err); code != codes.DeadlineExceeded {
+		t.Fatalf("PollTime code = %v, want %v (%v)", code, codes.DeadlineExceeded, err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("PollTime took %v", d)
+	}
+
+	// the server implementation sees the call given up
+	select {
+	case <-hw.cancelled:
+	case <-time.After(2 * time.Second):
+		t.Error("server call was not cancelled")
+	}
+}
+
+// v1Server serves only the RPCs of protocol version 1
+type v1Server struct {
+	proto.UnimplementedHWServiceServer
+	s *GRPCServer
+}
+
+func (v *v1Server) PollTime(ctx context.Context, e *empty.Empty) (*proto.PollTimeReply, error) {
+	return v.s.PollTime(ctx, e)
+}
+
+func (v *v1Server) Sensors(e *empty.Empty, stream proto.HWService_SensorsServer) error {
+	return v.s.Sensors(e, stream)
+}
+
+func (v *v1Server) ReadingsForSensorID(req *proto.SensorIDRequest, stream proto.HWService_ReadingsForSensorIDServer) error {
+	return v.s.ReadingsForSensorID(req, stream)
+}
+
+func TestLegacyClient(t *testing.T) {
+	ctx := context.Background()
+	conn := serveTest(t, &v1Server{s: &GRPCServer{Impl: &pollingService{}}})
+
+	raw, err := (&HardwareServicePlugin{Version: ProtocolVersion1}).GRPCClient(ctx, nil, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hw := raw.(HardwareService)
+	if v := hw.(Versioned).ProtocolVersion(); v != ProtocolVersion1 {
+		t.Errorf("ProtocolVersion = %d, want %d", v, ProtocolVersion1)
+	}
+
+	results, err := hw.ReadingsByKeys(ctx, []ReadingKey{{"100", 1}, {"100", 3}})
+	if err != nil {
+		t.Fatalf("ReadingsByKeys: %v", err)
+	}
+	if len(results) != 2 || results[0].Err != nil || !errors.Is(results[1].Err, ErrReadingNotFound) {
+		t.Errorf("results = %+v", results)
+	}
+
+	// a version 2 server predating Status is active while it polls
+	st, err := (&GRPCClient{Client: proto.NewHWServiceClient(conn), Version: ProtocolVersion2}).Status(ctx)
+	if err != nil || st.State != StateActive || st.PollTime == 0 || st.Sensors == 0 {
+		t.Errorf("Status = %+v, %v", st, err)
+	}
+	// a server without History keeps no history
+	_, err = (&GRPCClient{Client: proto.NewHWServiceClient(conn)}).History(ctx, ReadingKey{"100", 1}, time.Time{}, 0)
+	if !errors.Is(err, ErrNoHistory) {
+		t.Errorf("History err = %v, want ErrNoHistory", err)
+	}
+	// nor FindReadings, the sensors are walked instead
+	found, err := (&GRPCClient{Client: proto.NewHWServiceClient(conn)}).FindReadings(ctx, Query{Text: "cpu", Limit: 1})
+	if err != nil || len(found) != 1 || found[0].Key() != (ReadingKey{"100", 1}) {
+		t.Errorf("FindReadings = %v, %v", found, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	ch, err := Subscribe(ctx, hw, []ReadingKey{{"100", 1}}, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := <-ch; u.Err != nil || len(u.Readings) != 1 {
+		t.Errorf("update = %+v", u)
+	}
+}
+
+// plainServer answers with a status error without details, as a
+// server predating them does
+type plainServer struct {
+	proto.UnimplementedHWServiceServer
+}
+
+func (plainServer) PollTime(context.Context, *empty.Empty) (*proto.PollTimeReply, error) {
+	return nil, status.Error(codes.NotFound, "gone")
+}
+
+func TestStatusCodes(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"unavailable", fmt.Errorf("%w: shared memory inactive", ErrUnavailable), codes.Unavailable},
+		{"layout", fmt.Errorf("%w: unsupported version", ErrLayoutMismatch), codes.FailedPrecondition},
+		{"sensor", fmt.Errorf("%w: 100", ErrSensorNotFound), codes.NotFound},
+		{"reading", fmt.Errorf("%w: 100:1", ErrReadingNotFound), codes.NotFound},
+		{"no sources", ErrNoSources, codes.Unavailable},
+	}
+	for _, tt := range tests {
+		conn := serveTest(t, &GRPCServer{Impl: &fakeService{err: tt.err}})
+		c := &GRPCClient{Client: proto.NewHWServiceClient(conn)}
+		_, err := c.Sensors(ctx)
+		if code := status.Code(err); code != tt.code {
+			t.Errorf("%s: code = %v, want %v", tt.name, code, tt.code)
+		}
+		for _, sc := range statusCodes {
+			if got, want := errors.Is(err, sc.err), errors.Is(tt.err, sc.err); got != want {
+				t.Errorf("%s: errors.Is(%v, %v) = %v, want %v", tt.name, err, sc.err, got, want)
+			}
+		}
+	}
+
+	// a code without a reason maps to the first error of the code
+	conn := serveTest(t, plainServer{})
+	_, err := (&GRPCClient{Client: proto.NewHWServiceClient(conn)}).PollTime(ctx)
+	if !errors.Is(err, ErrSensorNotFound) || status.Code(err) != codes.NotFound {
+		t.Errorf("PollTime err = %v, want ErrSensorNotFound", err)
+	}
+}
@@ -0,0 +1,34 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoHistory the service keeps no history of its readings
+var ErrNoHistory = errors.New("no reading history")
+
+// Sample a value of a reading at a poll
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// Historian is implemented by services keeping a history of readings,
+// e.g. for tiles to prefill their graph
+type Historian interface {
+	// History samples of key taken at or after since, oldest first,
+	// at most the newest maxPoints unless maxPoints is 0
+	History(ctx context.Context, key ReadingKey, since time.Time, maxPoints int) ([]Sample, error)
+}
+
+// History asks hw for the history of key, ErrNoHistory when
+// hw is not a Historian
+func History(ctx context.Context, hw HardwareService, key ReadingKey, since time.Time, maxPoints int) ([]Sample, error) {
+	h, ok := hw.(Historian)
+	if !ok {
+		return nil, ErrNoHistory
+	}
+	return h.History(ctx, key, since, maxPoints)
+}
@@ -2,6 +2,7 @@ package hwsensorsservice
 
 import (
 	"context"
+	"time"
 
 	"google.golang.org/grpc"
 
@@ -9,24 +10,63 @@ import (
 	"github.com/shayne/hwinfo-streamdeck/pkg/service/proto"
 )
 
+// Protocol versions negotiated between hwinfo.exe and hwinfo-plugin.exe,
+// both pick the highest version they have in common
+const (
+	// ProtocolVersion1 PollTime, Sensors and ReadingsForSensorID
+	ProtocolVersion1 = 1
+	// ProtocolVersion2 adds ReadingsByKeys and Subscribe. Calls added
+	// since answer Unimplemented from plugins predating them, which
+	// clients take as not supported:
+	//   - Status, reported as by a version 1 plugin
+	//   - History, ErrNoHistory
+	//   - FindReadings, by walking the sensors
+	ProtocolVersion2 = 2
+	// ProtocolVersion the latest version
+	ProtocolVersion = ProtocolVersion2
+)
+
+// PluginName name the hardware service is dispensed as
+const PluginName = "hwinfoplugin"
+
 // Handshake is a common handshake that is shared by plugin and host.
 var Handshake = plugin.HandshakeConfig{
-	// This isn't required when using VersionedPlugins
-	ProtocolVersion:  1,
+	// Only used by peers predating VersionedPlugins, which speak version 1
+	ProtocolVersion:  ProtocolVersion1,
 	MagicCookieKey:   "BASIC_PLUGIN",
 	MagicCookieValue: "hello",
 }
 
-// PluginMap is the map of plugins we can dispense.
+// PluginMap is the map of plugins we can dispense at protocol version 1.
 var PluginMap = map[string]plugin.Plugin{
-	"hwinfoplugin": &HardwareServicePlugin{},
+	PluginName: &HardwareServicePlugin{Version: ProtocolVersion1},
+}
+
+// VersionedPlugins the plugin sets of every protocol version
+// the host speaks
+var VersionedPlugins = VersionedPluginsFor(nil)
+
+// VersionedPluginsFor the plugin sets of every protocol version serving impl
+func VersionedPluginsFor(impl HardwareService) map[int]plugin.PluginSet {
+	sets := make(map[int]plugin.PluginSet)
+	for _, v := range []int{ProtocolVersion1, ProtocolVersion2} {
+		sets[v] = plugin.PluginSet{PluginName: &HardwareServicePlugin{Impl: impl, Version: v}}
+	}
+	return sets
 }
 
 // HardwareService is the interface that we're exposing as a plugin.
+// Every call gives up with ctx.Err() once ctx is done.
 type HardwareService interface {
-	PollTime() (uint64, error)
-	Sensors() ([]Sensor, error)
-	ReadingsForSensorID(id string) ([]Reading, error)
+	PollTime(ctx context.Context) (uint64, error)
+	Sensors(ctx context.Context) ([]Sensor, error)
+	ReadingsForSensorID(ctx context.Context, id string) ([]Reading, error)
+	// ReadingsByKeys looks up readings across sensors in one call,
+	// results are in the order of keys
+	ReadingsByKeys(ctx context.Context, keys []ReadingKey) ([]ReadingResult, error)
+	// Status of the service and its source, errors only
+	// when the status itself cannot be determined
+	Status(ctx context.Context) (Status, error)
 }
 
 // HardwareServicePlugin is the implementation of plugin.GRPCPlugin so we can serve/consume this.
@@ -36,6 +76,11 @@ type HardwareServicePlugin struct {
 	// Concrete implementation, written in Go. This is only used for plugins
 	// that are written in Go.
 	Impl HardwareService
+	// Timeout of the client's calls, DefaultTimeout when zero
+	Timeout time.Duration
+	// Version protocol version of the plugin set, the client
+	// adapts the API for plugins speaking an older version
+	Version int
 }
 
 // GRPCServer constructor
@@ -46,13 +91,83 @@ func (p *HardwareServicePlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Se
 
 // GRPCClient constructor
 func (p *HardwareServicePlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
-	return &GRPCClient{Client: proto.NewHWServiceClient(c)}, nil
+	timeout := p.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	client := &GRPCClient{Client: proto.NewHWServiceClient(c), Timeout: timeout, Version: p.Version}
+	if p.Version == ProtocolVersion1 {
+		return &legacyClient{c: client}, nil
+	}
+	return client, nil
+}
+
+// Versioned is implemented by services knowing the protocol version
+// they speak, for diagnostics
+type Versioned interface {
+	// ProtocolVersion negotiated version, 0 when unknown
+	ProtocolVersion() int
+}
+
+// legacyClient talks to a plugin speaking protocol version 1, which
+// serves neither ReadingsByKeys, Subscribe nor Status. It is not a Subscriber,
+// subscriptions poll it instead.
+type legacyClient struct {
+	c *GRPCClient
+}
+
+func (l *legacyClient) PollTime(ctx context.Context) (uint64, error) {
+	return l.c.PollTime(ctx)
+}
+
+func (l *legacyClient) Sensors(ctx context.Context) ([]Sensor, error) {
+	return l.c.Sensors(ctx)
+}
+
+func (l *legacyClient) ReadingsForSensorID(ctx context.Context, id string) ([]Reading, error) {
+	return l.c.ReadingsForSensorID(ctx, id)
+}
+
+func (l *legacyClient) ReadingsByKeys(ctx context.Context, keys []ReadingKey) ([]ReadingResult, error) {
+	return LookupReadings(keys, func(id string) ([]Reading, error) {
+		return l.c.ReadingsForSensorID(ctx, id)
+	}), nil
+}
+
+// Status as far as a version 1 plugin tells, without backend,
+// shared memory version or read times
+func (l *legacyClient) Status(ctx context.Context) (Status, error) {
+	var st Status
+	t, err := l.c.PollTime(ctx)
+	if err != nil {
+		st.LastError = err.Error()
+		return st, nil
+	}
+	st.State, st.PollTime = StateActive, t
+	sensors, err := l.c.Sensors(ctx)
+	if err != nil {
+		return st, err
+	}
+	st.Sensors = len(sensors)
+	return st, nil
 }
 
+func (l *legacyClient) ProtocolVersion() int {
+	return ProtocolVersion1
+}
+
+// SchemeSeparator ends the backend scheme of sensor IDs, e.g.
+// hwmon:nct6798/nct6775.656. Source names can not contain it, so IDs
+// of the default source holding SourceSeparator are never taken for
+// IDs of a named source.
+const SchemeSeparator = ":"
+
 // Sensor is the common hardware interface for a sensor
 type Sensor interface {
 	ID() string
 	Name() string
+	// NameUser name as renamed by the user, same as Name when not renamed
+	NameUser() string
 }
 
 // ReadingType enum of value/unit type for reading
@@ -89,6 +204,8 @@ type Reading interface {
 	TypeI() int32
 	Type() string
 	Label() string
+	// LabelUser label as renamed by the user, same as Label when not renamed
+	LabelUser() string
 	Unit() string
 	Value() float64
 	ValueMin() float64
@@ -108,6 +225,10 @@ func (s sensor) Name() string {
 	return s.Sensor.GetName()
 }
 
+func (s sensor) NameUser() string {
+	return s.Sensor.GetNameUser()
+}
+
 type reading struct {
 	*proto.Reading
 }
@@ -120,6 +241,10 @@ func (r reading) Label() string {
 	return r.Reading.GetLabel()
 }
 
+func (r reading) LabelUser() string {
+	return r.Reading.GetLabelUser()
+}
+
 func (r reading) Type() string {
 	return r.Reading.GetType()
 }
@@ -0,0 +1,64 @@
+package hwsensorsservice
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrReadingNotFound no current reading for a key of ReadingsByKeys
+var ErrReadingNotFound = errors.New("reading not found")
+
+// ReadingKey identifies a reading, Sensor.ID together with Reading.ID
+type ReadingKey struct {
+	SensorID  string
+	ReadingID int32
+}
+
+func (k ReadingKey) String() string {
+	return fmt.Sprintf("%s:%d", k.SensorID, k.ReadingID)
+}
+
+// ReadingResult the reading of a key of ReadingsByKeys,
+// Reading is nil when Err is set
+type ReadingResult struct {
+	Key     ReadingKey
+	Reading Reading
+	Err     error
+}
+
+// LookupReadings implements ReadingsByKeys on top of a
+// ReadingsForSensorID, asking for the readings of every sensor once.
+// Keys of sensors that do not exist (ErrSensorNotFound) are reported
+// as not found, keys of sensors that cannot be read get the error.
+func LookupReadings(keys []ReadingKey, readingsForSensorID func(id string) ([]Reading, error)) []ReadingResult {
+	type sensorReadings struct {
+		readings map[int32]Reading
+		err      error
+	}
+	bySensor := make(map[string]*sensorReadings)
+	results := make([]ReadingResult, 0, len(keys))
+	for _, k := range keys {
+		sr, ok := bySensor[k.SensorID]
+		if !ok {
+			sr = &sensorReadings{readings: make(map[int32]Reading)}
+			rs, err := readingsForSensorID(k.SensorID)
+			if err != nil && !errors.Is(err, ErrSensorNotFound) {
+				sr.err = fmt.Errorf("sensor %s: %w", k.SensorID, err)
+			}
+			for _, r := range rs {
+				sr.readings[r.ID()] = r
+			}
+			bySensor[k.SensorID] = sr
+		}
+		res := ReadingResult{Key: k}
+		if r, ok := sr.readings[k.ReadingID]; ok {
+			res.Reading = r
+		} else if sr.err != nil {
+			res.Err = sr.err
+		} else {
+			res.Err = fmt.Errorf("%w: %s", ErrReadingNotFound, k)
+		}
+		results = append(results, res)
+	}
+	return results
+}
@@ -0,0 +1,48 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestLookupReadings(t *testing.T) {
+	calls := make(map[string]int)
+	results := LookupReadings([]ReadingKey{{"100", 1}, {"100", 3}, {"200", 1}, {"300", 1}, {"200", 2}}, func(id string) ([]Reading, error) {
+		calls[id]++
+		switch id {
+		case "100":
+			return []Reading{fakeReading{1, 42}}, nil
+		case "200":
+			return nil, fmt.Errorf("%w: plugin restarting", ErrUnavailable)
+		case "300":
+			return nil, fmt.Errorf("%w: %s", ErrSensorNotFound, id)
+		}
+		return nil, context.Canceled
+	})
+
+	for id, n := range calls {
+		if n != 1 {
+			t.Errorf("sensor %s asked %d times", id, n)
+		}
+	}
+	if len(results) != 5 {
+		t.Fatalf("got %d results, want 5", len(results))
+	}
+	if r := results[0]; r.Err != nil || r.Reading.Value() != 42 {
+		t.Errorf("100:1 = %+v", r)
+	}
+	for _, i := range []int{1, 3} {
+		if err := results[i].Err; !errors.Is(err, ErrReadingNotFound) {
+			t.Errorf("%v err = %v, want ErrReadingNotFound", results[i].Key, err)
+		}
+	}
+	// a sensor that cannot be read says nothing about its readings
+	for _, i := range []int{2, 4} {
+		err := results[i].Err
+		if !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrReadingNotFound) {
+			t.Errorf("%v err = %v, want ErrUnavailable only", results[i].Key, err)
+		}
+	}
+}
@@ -26,6 +26,11 @@ type HWServiceClient interface {
 	PollTime(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PollTimeReply, error)
 	Sensors(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (HWService_SensorsClient, error)
 	ReadingsForSensorID(ctx context.Context, in *SensorIDRequest, opts ...grpc.CallOption) (HWService_ReadingsForSensorIDClient, error)
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (HWService_SubscribeClient, error)
+	ReadingsByKeys(ctx context.Context, in *ReadingsByKeysRequest, opts ...grpc.CallOption) (*ReadingsByKeysReply, error)
+	Status(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatusReply, error)
+	History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryReply, error)
+	FindReadings(ctx context.Context, in *FindReadingsRequest, opts ...grpc.CallOption) (*FindReadingsReply, error)
 }
 
 type hWServiceClient struct {
@@ -109,6 +114,74 @@ func (x *hWServiceReadingsForSensorIDClient) Recv() (*Reading, error) {
 	return m, nil
 }
 
+func (c *hWServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (HWService_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &HWService_ServiceDesc.Streams[2], "/proto.HWService/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &hWServiceSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type HWService_SubscribeClient interface {
+	Recv() (*ReadingUpdate, error)
+	grpc.ClientStream
+}
+
+type hWServiceSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *hWServiceSubscribeClient) Recv() (*ReadingUpdate, error) {
+	m := new(ReadingUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *hWServiceClient) ReadingsByKeys(ctx context.Context, in *ReadingsByKeysRequest, opts ...grpc.CallOption) (*ReadingsByKeysReply, error) {
+	out := new(ReadingsByKeysReply)
+	err := c.cc.Invoke(ctx, "/proto.HWService/ReadingsByKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hWServiceClient) Status(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatusReply, error) {
+	out := new(StatusReply)
+	err := c.cc.Invoke(ctx, "/proto.HWService/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hWServiceClient) History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryReply, error) {
+	out := new(HistoryReply)
+	err := c.cc.Invoke(ctx, "/proto.HWService/History", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hWServiceClient) FindReadings(ctx context.Context, in *FindReadingsRequest, opts ...grpc.CallOption) (*FindReadingsReply, error) {
+	out := new(FindReadingsReply)
+	err := c.cc.Invoke(ctx, "/proto.HWService/FindReadings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // HWServiceServer is the server API for HWService service.
 // All implementations must embed UnimplementedHWServiceServer
 // for forward compatibility
@@ -116,6 +189,11 @@ type HWServiceServer interface {
 	PollTime(context.Context, *emptypb.Empty) (*PollTimeReply, error)
 	Sensors(*emptypb.Empty, HWService_SensorsServer) error
 	ReadingsForSensorID(*SensorIDRequest, HWService_ReadingsForSensorIDServer) error
+	Subscribe(*SubscribeRequest, HWService_SubscribeServer) error
+	ReadingsByKeys(context.Context, *ReadingsByKeysRequest) (*ReadingsByKeysReply, error)
+	Status(context.Context, *emptypb.Empty) (*StatusReply, error)
+	History(context.Context, *HistoryRequest) (*HistoryReply, error)
+	FindReadings(context.Context, *FindReadingsRequest) (*FindReadingsReply, error)
 	mustEmbedUnimplementedHWServiceServer()
 }
 
@@ -132,6 +210,21 @@ func (UnimplementedHWServiceServer) Sensors(*emptypb.Empty, HWService_SensorsSer
 func (UnimplementedHWServiceServer) ReadingsForSensorID(*SensorIDRequest, HWService_ReadingsForSensorIDServer) error {
 	return status.Errorf(codes.Unimplemented, "method ReadingsForSensorID not implemented")
 }
+func (UnimplementedHWServiceServer) Subscribe(*SubscribeRequest, HWService_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedHWServiceServer) ReadingsByKeys(context.Context, *ReadingsByKeysRequest) (*ReadingsByKeysReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadingsByKeys not implemented")
+}
+func (UnimplementedHWServiceServer) Status(context.Context, *emptypb.Empty) (*StatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedHWServiceServer) History(context.Context, *HistoryRequest) (*HistoryReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method History not implemented")
+}
+func (UnimplementedHWServiceServer) FindReadings(context.Context, *FindReadingsRequest) (*FindReadingsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindReadings not implemented")
+}
 func (UnimplementedHWServiceServer) mustEmbedUnimplementedHWServiceServer() {}
 
 // UnsafeHWServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -205,6 +298,99 @@ func (x *hWServiceReadingsForSensorIDServer) Send(m *Reading) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _HWService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HWServiceServer).Subscribe(m, &hWServiceSubscribeServer{stream})
+}
+
+type HWService_SubscribeServer interface {
+	Send(*ReadingUpdate) error
+	grpc.ServerStream
+}
+
+type hWServiceSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *hWServiceSubscribeServer) Send(m *ReadingUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _HWService_ReadingsByKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadingsByKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HWServiceServer).ReadingsByKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.HWService/ReadingsByKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HWServiceServer).ReadingsByKeys(ctx, req.(*ReadingsByKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HWService_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HWServiceServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.HWService/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HWServiceServer).Status(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HWService_History_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HWServiceServer).History(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.HWService/History",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HWServiceServer).History(ctx, req.(*HistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HWService_FindReadings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindReadingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HWServiceServer).FindReadings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.HWService/FindReadings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HWServiceServer).FindReadings(ctx, req.(*FindReadingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // HWService_ServiceDesc is the grpc.ServiceDesc for HWService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -216,6 +402,22 @@ var HWService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PollTime",
 			Handler:    _HWService_PollTime_Handler,
 		},
+		{
+			MethodName: "ReadingsByKeys",
+			Handler:    _HWService_ReadingsByKeys_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _HWService_Status_Handler,
+		},
+		{
+			MethodName: "History",
+			Handler:    _HWService_History_Handler,
+		},
+		{
+			MethodName: "FindReadings",
+			Handler:    _HWService_FindReadings_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -228,6 +430,11 @@ var HWService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _HWService_ReadingsForSensorID_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _HWService_Subscribe_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "pkg/service/proto/hwservice.proto",
 }
@@ -0,0 +1,163 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidQuery the query can not be run, e.g. its pattern does not compile
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Query selects readings by FindReadings, zero fields match any reading
+type Query struct {
+	// Types of reading, any type when empty
+	Types []ReadingType
+	// Unit of the reading, case insensitive
+	Unit string
+	// Text case insensitive substring of the label or sensor name,
+	// as reported or as renamed by the user
+	Text string
+	// Pattern regular expression matched against the label or sensor
+	// name, as reported or as renamed by the user
+	Pattern string
+	// Min and Max bound the current value, inclusive
+	Min, Max *float64
+	// Limit matches returned at most, all when 0
+	Limit int
+}
+
+// Found a reading matched by FindReadings along with its sensor
+type Found struct {
+	Sensor  Sensor
+	Reading Reading
+}
+
+// Key of the found reading
+func (f Found) Key() ReadingKey {
+	return ReadingKey{SensorID: f.Sensor.ID(), ReadingID: f.Reading.ID()}
+}
+
+// Finder is implemented by services searching their readings without
+// the caller asking for every sensor, e.g. across the wire
+type Finder interface {
+	FindReadings(ctx context.Context, q Query) ([]Found, error)
+}
+
+// FindReadings the readings of hw matching q in sensor order, natively
+// when hw is a Finder and by walking its sensors otherwise
+func FindReadings(ctx context.Context, hw HardwareService, q Query) ([]Found, error) {
+	if f, ok := hw.(Finder); ok {
+		return f.FindReadings(ctx, q)
+	}
+	return WalkFindReadings(ctx, hw, q)
+}
+
+// WalkFindReadings implements FindReadings on top of hw by asking for
+// the readings of every sensor
+func WalkFindReadings(ctx context.Context, hw HardwareService, q Query) ([]Found, error) {
+	if _, err := q.matcher(); err != nil {
+		return nil, err
+	}
+	sensors, err := hw.Sensors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return MatchReadings(q, sensors, func(id string) ([]Reading, error) {
+		return hw.ReadingsForSensorID(ctx, id)
+	})
+}
+
+// MatchReadings the readings of sensors matching q, asking
+// readingsForSensorID for the readings of each sensor in turn.
+// Sensors currently unavailable are skipped.
+func MatchReadings(q Query, sensors []Sensor, readingsForSensorID func(id string) ([]Reading, error)) ([]Found, error) {
+	m, err := q.matcher()
+	if err != nil {
+		return nil, err
+	}
+	var found []Found
+	for _, s := range sensors {
+		readings, err := readingsForSensorID(s.ID())
+		if errors.Is(err, ErrUnavailable) {
+			// e.g. a scrape target down, the other sensors are searched
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sensor %s: %w", s.ID(), err)
+		}
+		for _, r := range readings {
+			if !m.match(s, r) {
+				continue
+			}
+			found = append(found, Found{Sensor: s, Reading: r})
+			if q.Limit > 0 && len(found) == q.Limit {
+				return found, nil
+			}
+		}
+	}
+	return found, nil
+}
+
+// queryMatcher a Query prepared for matching
+type queryMatcher struct {
+	q     Query
+	types map[int32]bool
+	text  string
+	re    *regexp.Regexp
+}
+
+func (q Query) matcher() (*queryMatcher, error) {
+	m := &queryMatcher{q: q, text: strings.ToLower(q.Text)}
+	if len(q.Types) > 0 {
+		m.types = make(map[int32]bool, len(q.Types))
+		for _, t := range q.Types {
+			m.types[int32(t)] = true
+		}
+	}
+	if q.Pattern != "" {
+		re, err := regexp.Compile(q.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+		}
+		m.re = re
+	}
+	if q.Min != nil && q.Max != nil && *q.Min > *q.Max {
+		return nil, fmt.Errorf("%w: min %v above max %v", ErrInvalidQuery, *q.Min, *q.Max)
+	}
+	return m, nil
+}
+
+func (m *queryMatcher) match(s Sensor, r Reading) bool {
+	if m.types != nil && !m.types[r.TypeI()] {
+		return false
+	}
+	if m.q.Unit != "" && !strings.EqualFold(r.Unit(), m.q.Unit) {
+		return false
+	}
+	if m.q.Min != nil && r.Value() < *m.q.Min {
+		return false
+	}
+	if m.q.Max != nil && r.Value() > *m.q.Max {
+		return false
+	}
+	names := [...]string{r.Label(), r.LabelUser(), s.Name(), s.NameUser()}
+	if m.text != "" && !anyName(names[:], func(n string) bool { return strings.Contains(strings.ToLower(n), m.text) }) {
+		return false
+	}
+	if m.re != nil && !anyName(names[:], m.re.MatchString) {
+		return false
+	}
+	return true
+}
+
+func anyName(names []string, f func(string) bool) bool {
+	for _, n := range names {
+		if f(n) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/shayne/hwinfo-streamdeck/pkg/service/proto"
+)
+
+type queryReading struct {
+	id    int32
+	typ   ReadingType
+	label string
+	unit  string
+	value float64
+}
+
+func (r queryReading) ID() int32         { return r.id }
+func (r queryReading) TypeI() int32      { return int32(r.typ) }
+func (r queryReading) Type() string      { return r.typ.String() }
+func (r queryReading) Label() string     { return r.label }
+func (r queryReading) LabelUser() string { return r.label }
+func (r queryReading) Unit() string      { return r.unit }
+func (r queryReading) Value() float64    { return r.value }
+func (r queryReading) ValueMin() float64 { return r.value }
+func (r queryReading) ValueMax() float64 { return r.value }
+func (r queryReading) ValueAvg() float64 { return r.value }
+
+// queryService serves fixed readings per sensor
+type queryService struct {
+	fakeService
+	readings map[string][]Reading
+}
+
+func (q *queryService) ReadingsForSensorID(_ context.Context, id string) ([]Reading, error) {
+	r, ok := q.readings[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: readings for sensor id %s do not exist", ErrSensorNotFound, id)
+	}
+	return r, nil
+}
+
+func TestFindReadings(t *testing.T) {
+	ctx := context.Background()
+	local := &queryService{
+		fakeService: fakeService{sensors: []Sensor{fakeSensor{"100", "CPU [#0]"}, fakeSensor{"101", "Drive"}}},
+		readings: map[string][]Reading{
+			"100": {
+				queryReading{1, ReadingTypeTemp, "Core Max", "°C", 85},
+				queryReading{2, ReadingTypeTemp, "Core Avg", "°C", 60},
+				queryReading{3, ReadingTypeClock, "Core Clock", "MHz", 4200},
+			},
+			"101": {queryReading{1, ReadingTypeTemp, "Drive Temperature", "°C", 41}},
+		},
+	}
+	lab := &queryService{
+		fakeService: fakeService{sensors: []Sensor{fakeSensor{"200", "GPU"}}},
+		readings: map[string][]Reading{
+			"200": {
+				queryReading{1, ReadingTypeTemp, "GPU Hot Spot", "°C", 92},
+				queryReading{2, ReadingTypePower, "GPU Power", "W", 250},
+			},
+		},
+	}
+	// lab across the wire, served and searched by FindReadings
+	conn := serveTest(t, &GRPCServer{Impl: lab})
+	c := NewComposite()
+	if err := c.Add("", local); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add("lab", &GRPCClient{Client: proto.NewHWServiceClient(conn)}); err != nil {
+		t.Fatal(err)
+	}
+
+	hot := 80.0
+	tests := []struct {
+		name string
+		q    Query
+		want []ReadingKey
+	}{
+		{"all", Query{}, []ReadingKey{{"100", 1}, {"100", 2}, {"100", 3}, {"101", 1}, {"lab/200", 1}, {"lab/200", 2}}},
+		{"hot temps", Query{Types: []ReadingType{ReadingTypeTemp}, Min: &hot}, []ReadingKey{{"100", 1}, {"lab/200", 1}}},
+		{"unit", Query{Unit: "w"}, []ReadingKey{{"lab/200", 2}}},
+		{"text", Query{Text: "core"}, []ReadingKey{{"100", 1}, {"100", 2}, {"100", 3}}},
+		{"none", Query{Text: "gpu", Max: &hot}, nil},
+		{"pattern", Query{Pattern: `^(Drive|GPU)$`}, []ReadingKey{{"101", 1}, {"lab/200", 1}, {"lab/200", 2}}},
+		{"limit", Query{Types: []ReadingType{ReadingTypeTemp}, Limit: 4}, []ReadingKey{{"100", 1}, {"100", 2}, {"101", 1}, {"lab/200", 1}}},
+	}
+	for _, tt := range tests {
+		found, err := FindReadings(ctx, c, tt.q)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var got []ReadingKey
+		for _, f := range found {
+			got = append(got, f.Key())
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	found, err := FindReadings(ctx, c, Query{Text: "hot spot"})
+	if err != nil || len(found) != 1 {
+		t.Fatalf("hot spot: %v, %v", found, err)
+	}
+	if name := found[0].Sensor.Name(); name != "lab: GPU" {
+		t.Errorf("sensor name = %q, want the source prefixed", name)
+	}
+	if v := found[0].Reading.Value(); v != 92 {
+		t.Errorf("value = %v, want 92", v)
+	}
+
+	if _, err := FindReadings(ctx, c, Query{Pattern: "("}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("bad pattern: got %v, want ErrInvalidQuery", err)
+	}
+	if _, err := FindReadings(ctx, lab, Query{Pattern: "("}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("bad pattern walking: got %v, want ErrInvalidQuery", err)
+	}
+}
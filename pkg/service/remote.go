@@ -0,0 +1,227 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/shayne/hwinfo-streamdeck/pkg/service/proto"
+)
+
+// DefaultPort of a standalone server
+const DefaultPort = "7878"
+
+// NewServer creates a gRPC server serving impl to remote clients, in
+// contrast to the go-plugin server talking to a parent process. With a
+// token set every call must carry it as bearer token, see Dial. tlsConfig
+// nil serves plain text, only meant for trusted networks.
+func NewServer(impl HardwareService, tlsConfig *tls.Config, token string) *grpc.Server {
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	if token != "" {
+		auth := tokenAuth(token)
+		opts = append(opts,
+			grpc.UnaryInterceptor(auth.unary),
+			grpc.StreamInterceptor(auth.stream),
+		)
+	}
+	s := grpc.NewServer(opts...)
+	proto.RegisterHWServiceServer(s, &GRPCServer{Impl: impl})
+	return s
+}
+
+// tokenAuth checks the bearer token of incoming calls
+type tokenAuth string
+
+func (t tokenAuth) check(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		if !strings.HasPrefix(v, "Bearer ") {
+			continue
+		}
+		got := strings.TrimPrefix(v, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(t)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid or missing token")
+}
+
+func (t tokenAuth) unary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := t.check(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (t tokenAuth) stream(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := t.check(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// tokenCredentials sends the bearer token with every call
+type tokenCredentials struct {
+	token  string
+	secure bool
+}
+
+func (c tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return c.secure
+}
+
+// DialOptions configures Dial
+type DialOptions struct {
+	// Token bearer token the server expects
+	Token string
+	// CertFingerprint hex SHA-256 of the server certificate, pins a
+	// self-signed certificate instead of verifying it against CAs
+	CertFingerprint string
+	// RootCAs verify the server certificate, system roots when nil
+	RootCAs *x509.CertPool
+	// Insecure plain text, the token is sent in the clear
+	Insecure bool
+	// Timeout of the client's calls, DefaultTimeout when zero
+	Timeout time.Duration
+}
+
+// Dial connects to a standalone server at addr, host:port, DefaultPort
+// when no port is given. The connection is established lazily and
+// re-established by gRPC when it breaks. Close the returned
+// connection once done.
+func Dial(addr string, opts DialOptions) (*GRPCClient, *grpc.ClientConn, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, DefaultPort)
+	}
+
+	var dopts []grpc.DialOption
+	if opts.Insecure {
+		dopts = append(dopts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	} else {
+		cfg := &tls.Config{RootCAs: opts.RootCAs, MinVersion: tls.VersionTLS12}
+		if opts.CertFingerprint != "" {
+			want, err := hex.DecodeString(strings.ReplaceAll(opts.CertFingerprint, ":", ""))
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid certificate fingerprint: %w", err)
+			}
+			// the pinned certificate replaces chain and host name checks
+			cfg.InsecureSkipVerify = true
+			cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+				if len(rawCerts) == 0 {
+					return errors.New("server sent no certificate")
+				}
+				got := sha256.Sum256(rawCerts[0])
+				if subtle.ConstantTimeCompare(got[:], want) != 1 {
+					return fmt.Errorf("server certificate fingerprint %x does not match", got)
+				}
+				return nil
+			}
+		}
+		dopts = append(dopts, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	}
+	if opts.Token != "" {
+		dopts = append(dopts, grpc.WithPerRPCCredentials(tokenCredentials{token: opts.Token, secure: !opts.Insecure}))
+	}
+
+	conn, err := grpc.Dial(addr, dopts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	return &GRPCClient{Client: proto.NewHWServiceClient(conn), Timeout: timeout}, conn, nil
+}
+
+// LoadOrCreateCertificate loads the TLS certificate at certFile and
+// keyFile, bootstrapping a self-signed certificate for hosts when
+// neither exists yet. Clients pin it with CertFingerprint.
+func LoadOrCreateCertificate(certFile, keyFile string, hosts []string) (tls.Certificate, error) {
+	_, certErr := os.Stat(certFile)
+	_, keyErr := os.Stat(keyFile)
+	if errors.Is(certErr, os.ErrNotExist) && errors.Is(keyErr, os.ErrNotExist) {
+		if err := createCertificate(certFile, keyFile, hosts); err != nil {
+			return tls.Certificate{}, err
+		}
+	}
+	return tls.LoadX509KeyPair(certFile, keyFile)
+}
+
+func createCertificate(certFile, keyFile string, hosts []string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "hwinfo-plugin"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)
+}
+
+// CertFingerprint hex SHA-256 of the leaf certificate of cert,
+// as expected by DialOptions.CertFingerprint
+func CertFingerprint(cert tls.Certificate) string {
+	if len(cert.Certificate) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(cert.Certificate[0])
+	return hex.EncodeToString(sum[:])
+}
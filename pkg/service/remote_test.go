@@ -0,0 +1,101 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRemote(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cert, err := LoadOrCreateCertificate(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), []string{"127.0.0.1"})
+	if err != nil {
+		t.Fatalf("LoadOrCreateCertificate: %v", err)
+	}
+	// loads the bootstrapped certificate the second time
+	again, err := LoadOrCreateCertificate(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), nil)
+	if err != nil {
+		t.Fatalf("LoadOrCreateCertificate: %v", err)
+	}
+	if CertFingerprint(again) != CertFingerprint(cert) {
+		t.Fatal("certificate was not reused")
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hw := &fakeService{sensors: []Sensor{fakeSensor{"100", "CPU"}}}
+	srv := NewServer(hw, &tls.Config{Certificates: []tls.Certificate{cert}}, "secret")
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	tests := []struct {
+		name string
+		opts DialOptions
+		code codes.Code
+	}{
+		{"valid", DialOptions{Token: "secret", CertFingerprint: CertFingerprint(cert)}, codes.OK},
+		{"wrong token", DialOptions{Token: "guess", CertFingerprint: CertFingerprint(cert)}, codes.Unauthenticated},
+		{"no token", DialOptions{CertFingerprint: CertFingerprint(cert)}, codes.Unauthenticated},
+		{"wrong fingerprint", DialOptions{Token: "secret", CertFingerprint: "00"}, codes.Unavailable},
+		{"unknown CA", DialOptions{Token: "secret"}, codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, conn, err := Dial(lis.Addr().String(), tt.opts)
+			if err != nil {
+				t.Fatalf("Dial: %v", err)
+			}
+			defer conn.Close()
+
+			sensors, err := c.Sensors(ctx)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("Sensors code = %v, want %v (%v)", code, tt.code, err)
+			}
+			if err == nil && (len(sensors) != 1 || sensors[0].ID() != "100") {
+				t.Errorf("sensors = %v", sensors)
+			}
+			if err != nil {
+				return
+			}
+
+			// per-key errors keep their identity across the wire
+			results, err := c.ReadingsByKeys(ctx, []ReadingKey{{"100", 1}})
+			if err != nil {
+				t.Fatalf("ReadingsByKeys: %v", err)
+			}
+			if len(results) != 1 || !errors.Is(results[0].Err, ErrReadingNotFound) {
+				t.Errorf("results = %+v, want ErrReadingNotFound", results)
+			}
+		})
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	auth := tokenAuth("secret")
+	tests := []struct {
+		header string
+		ok     bool
+	}{
+		{"Bearer secret", true},
+		{"secret", false},
+		{"Basic secret", false},
+		{"Bearer secre", false},
+		{"bearer secret", false},
+	}
+	for _, tt := range tests {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", tt.header))
+		if err := auth.check(ctx); (err == nil) != tt.ok {
+			t.Errorf("%q: err = %v", tt.header, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoMatch no reading resembles the fingerprint closely enough
+var ErrNoMatch = errors.New("no matching reading")
+
+// Fingerprint describes a reading well enough to find it again after
+// HWiNFO renumbered sensor instances or reading IDs
+type Fingerprint struct {
+	SensorID   string `json:"sensorUid"`
+	SensorName string `json:"sensorName"`
+	ReadingID  int32  `json:"readingId"`
+	Label      string `json:"label"`
+	Unit       string `json:"unit"`
+	TypeI      int32  `json:"typeI"`
+}
+
+// NewFingerprint captures the identifying fields of a reading
+func NewFingerprint(s Sensor, r Reading) Fingerprint {
+	return Fingerprint{
+		SensorID:   s.ID(),
+		SensorName: s.Name(),
+		ReadingID:  r.ID(),
+		Label:      r.Label(),
+		Unit:       r.Unit(),
+		TypeI:      r.TypeI(),
+	}
+}
+
+// weights of matching fingerprint fields in percent, sum to 100
+const (
+	weightSensorID   = 10
+	weightSensorName = 25
+	weightReadingID  = 10
+	weightLabel      = 35
+	weightUnit       = 10
+	weightType       = 10
+)
+
+// DefaultMinConfidence matches below this are rejected. Candidates
+// always agree on the sensor name, besides that the label alone gets
+// them over it, or else the sensor and reading IDs, unit and type.
+const DefaultMinConfidence = .6
+
+// score confidence that sensor s reading r is the reading fp describes,
+// 1 for an identical fingerprint
+func (fp Fingerprint) score(s Sensor, r Reading) float64 {
+	var c int
+	if s.ID() == fp.SensorID {
+		c += weightSensorID
+	}
+	if s.Name() == fp.SensorName {
+		c += weightSensorName
+	}
+	if r.ID() == fp.ReadingID {
+		c += weightReadingID
+	}
+	if r.Label() == fp.Label {
+		c += weightLabel
+	}
+	if r.Unit() == fp.Unit {
+		c += weightUnit
+	}
+	if r.TypeI() == fp.TypeI {
+		c += weightType
+	}
+	return float64(c) / 100
+}
+
+// Match a reading found for a fingerprint
+type Match struct {
+	Sensor  Sensor
+	Reading Reading
+	// Confidence 0 to 1, 1 when every fingerprint field matched
+	Confidence float64
+}
+
+// Resolver re-binds saved readings to the current sensors
+type Resolver struct {
+	hw HardwareService
+	// MinConfidence matches below are reported as ErrNoMatch
+	MinConfidence float64
+}
+
+// NewResolver creates a Resolver looking up readings in hw
+func NewResolver(hw HardwareService) *Resolver {
+	return &Resolver{hw: hw, MinConfidence: DefaultMinConfidence}
+}
+
+// Fingerprint looks up the current reading for sensorID and readingID
+func (rs *Resolver) Fingerprint(ctx context.Context, sensorID string, readingID int32) (Fingerprint, error) {
+	sensors, err := rs.hw.Sensors(ctx)
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("Fingerprint Sensors: %w", err)
+	}
+	for _, s := range sensors {
+		if s.ID() != sensorID {
+			continue
+		}
+		readings, err := rs.hw.ReadingsForSensorID(ctx, sensorID)
+		if err != nil {
+			return Fingerprint{}, fmt.Errorf("Fingerprint ReadingsForSensorID: %w", err)
+		}
+		for _, r := range readings {
+			if r.ID() == readingID {
+				return NewFingerprint(s, r), nil
+			}
+		}
+	}
+	return Fingerprint{}, fmt.Errorf("%w: sensor %s reading %d", ErrNoMatch, sensorID, readingID)
+}
+
+// sourceRouter is implemented by services merging sources, e.g. Composite
+type sourceRouter interface {
+	SourceOf(id string) (string, error)
+}
+
+// Resolve finds the reading best matching fp among the sensors of the
+// same name and, for services merging sources, of the same source. A
+// reading is never re-bound to another sensor or machine. An unchanged
+// reading resolves with confidence 1, a reading that moved to another
+// sensor instance or ID resolves with less.
+func (rs *Resolver) Resolve(ctx context.Context, fp Fingerprint) (Match, error) {
+	router, _ := rs.hw.(sourceRouter)
+	var source string
+	if router != nil {
+		var err error
+		if source, err = router.SourceOf(fp.SensorID); err != nil {
+			return Match{}, fmt.Errorf("%w: %v", ErrNoMatch, err)
+		}
+	}
+	sensors, err := rs.hw.Sensors(ctx)
+	if err != nil {
+		return Match{}, fmt.Errorf("Resolve Sensors: %w", err)
+	}
+
+	var best Match
+	for _, s := range sensors {
+		if s.Name() != fp.SensorName {
+			continue
+		}
+		if router != nil {
+			if src, err := router.SourceOf(s.ID()); err != nil || src != source {
+				continue
+			}
+		}
+		readings, err := rs.hw.ReadingsForSensorID(ctx, s.ID())
+		if err != nil {
+			return Match{}, fmt.Errorf("Resolve ReadingsForSensorID: %w", err)
+		}
+		for _, r := range readings {
+			if c := fp.score(s, r); c > best.Confidence {
+				best = Match{Sensor: s, Reading: r, Confidence: c}
+			}
+		}
+		if best.Confidence == 1 {
+			break
+		}
+	}
+	if best.Reading == nil || best.Confidence < rs.MinConfidence {
+		return Match{}, fmt.Errorf("%w: %s %s (best confidence %.2f)", ErrNoMatch, fp.SensorName, fp.Label, best.Confidence)
+	}
+	return best, nil
+}
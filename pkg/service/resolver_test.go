@@ -0,0 +1,71 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	cpu := []Reading{
+		queryReading{1, ReadingTypeTemp, "Core Max", "°C", 70},
+		queryReading{2, ReadingTypeTemp, "Core Avg", "°C", 60},
+	}
+	local := &queryService{
+		fakeService: fakeService{sensors: []Sensor{fakeSensor{"100", "CPU [#0]"}}},
+		readings:    map[string][]Reading{"100": cpu},
+	}
+	lab := &queryService{
+		fakeService: fakeService{sensors: []Sensor{fakeSensor{"200", "GPU"}}},
+		readings:    map[string][]Reading{"200": {queryReading{1, ReadingTypeTemp, "GPU Temperature", "°C", 50}}},
+	}
+	c := NewComposite()
+	if err := c.Add("", local); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add("lab", lab); err != nil {
+		t.Fatal(err)
+	}
+
+	coreMax := Fingerprint{SensorID: "100", SensorName: "CPU [#0]", ReadingID: 1, Label: "Core Max", Unit: "°C", TypeI: int32(ReadingTypeTemp)}
+	moved := coreMax
+	moved.SensorID = "101"
+	renamed := coreMax
+	renamed.Label = "CPU Max"
+	// a sensor of lab gone, the local sensor of the same name must not take its place
+	crossSource := coreMax
+	crossSource.SensorID = "lab/100"
+	otherSensor := coreMax
+	otherSensor.SensorName = "CPU [#1]"
+	power := Fingerprint{SensorID: "100", SensorName: "CPU [#0]", ReadingID: 9, Label: "Package Power", Unit: "W", TypeI: int32(ReadingTypePower)}
+
+	tests := []struct {
+		name string
+		fp   Fingerprint
+		want ReadingKey
+		conf float64
+	}{
+		{"exact", coreMax, ReadingKey{"100", 1}, 1},
+		{"moved instance", moved, ReadingKey{"100", 1}, .9},
+		{"renamed label", renamed, ReadingKey{"100", 1}, .65},
+		{"cross source", crossSource, ReadingKey{}, 0},
+		{"other sensor name", otherSensor, ReadingKey{}, 0},
+		{"below threshold", power, ReadingKey{}, 0},
+	}
+	for _, tt := range tests {
+		m, err := NewResolver(c).Resolve(context.Background(), tt.fp)
+		if tt.conf == 0 {
+			if !errors.Is(err, ErrNoMatch) {
+				t.Errorf("%s: got %v %v, want ErrNoMatch", tt.name, m, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if k := (ReadingKey{m.Sensor.ID(), m.Reading.ID()}); k != tt.want || m.Confidence != tt.conf {
+			t.Errorf("%s: got %v confidence %.2f, want %v confidence %.2f", tt.name, k, m.Confidence, tt.want, tt.conf)
+		}
+	}
+}
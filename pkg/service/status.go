@@ -0,0 +1,60 @@
+package hwsensorsservice
+
+import "time"
+
+// BackendHWiNFO backend name of the HWiNFO shared memory backend
+const BackendHWiNFO = "hwinfo"
+
+// State of the hardware source behind a service
+type State int
+
+const (
+	// StateUnknown not read yet, or the last read failed otherwise
+	StateUnknown State = iota
+	// StateActive readings are current
+	StateActive
+	// StateDead HWiNFO shut down or switched off shared memory
+	// support, its signature reads DEAD
+	StateDead
+	// StateNotFound no HWiNFO shared memory, or the source of
+	// another backend cannot be reached
+	StateNotFound
+)
+
+func (s State) String() string {
+	return [...]string{"Unknown", "Active", "Dead", "NotFound"}[s]
+}
+
+// Status of a service, for diagnostics and error tiles
+type Status struct {
+	// Backend serving the readings, e.g. hwinfo or hwmon
+	Backend string
+	State   State
+	// Version and Revision of the HWiNFO shared memory,
+	// 0 for other backends
+	Version  int
+	Revision int
+	PollTime uint64
+	// LastRead time of the last successful read, zero if none yet
+	LastRead time.Time
+	// LastError of the last failed read, empty if none yet
+	LastError string
+	Sensors   int
+	Readings  int
+}
+
+// Age since the last successful read, 0 if none yet
+func (s Status) Age() time.Duration {
+	if s.LastRead.IsZero() {
+		return 0
+	}
+	return time.Since(s.LastRead)
+}
+
+// errString err's message, empty when nil
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
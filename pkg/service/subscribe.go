@@ -0,0 +1,89 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultSubscribeInterval how often PollSubscribe checks for a new
+// poll when the subscriber did not ask for a minimum interval
+const DefaultSubscribeInterval = 250 * time.Millisecond
+
+// Update the subscribed readings as of a poll
+type Update struct {
+	PollTime uint64
+	Readings map[ReadingKey]Reading
+	// Missing subscribed keys without a current reading
+	Missing []ReadingKey
+	// Err ends the subscription, it is set on the last update only
+	Err error
+}
+
+// Subscriber is implemented by services pushing reading updates
+// instead of being polled
+type Subscriber interface {
+	// Subscribe delivers the readings of keys whenever the poll time
+	// advances, at most once per minInterval. The channel is closed
+	// once ctx is done or the subscription failed.
+	Subscribe(ctx context.Context, keys []ReadingKey, minInterval time.Duration) (<-chan Update, error)
+}
+
+// Subscribe subscribes to hw, natively when it is a Subscriber and
+// with PollSubscribe otherwise
+func Subscribe(ctx context.Context, hw HardwareService, keys []ReadingKey, minInterval time.Duration) (<-chan Update, error) {
+	if s, ok := hw.(Subscriber); ok {
+		return s.Subscribe(ctx, keys, minInterval)
+	}
+	return PollSubscribe(ctx, hw, keys, minInterval), nil
+}
+
+// PollSubscribe implements a subscription on top of hw by checking its
+// poll time every minInterval. Failed polls are skipped, the
+// subscription only ends with ctx.
+func PollSubscribe(ctx context.Context, hw HardwareService, keys []ReadingKey, minInterval time.Duration) <-chan Update {
+	if minInterval <= 0 {
+		minInterval = DefaultSubscribeInterval
+	}
+	ch := make(chan Update)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(minInterval)
+		defer ticker.Stop()
+
+		var last uint64
+		for {
+			if t, err := hw.PollTime(ctx); err == nil && t != last {
+				last = t
+				select {
+				case ch <- readKeys(ctx, hw, t, keys):
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// readKeys looks up keys for an update
+func readKeys(ctx context.Context, hw HardwareService, pollTime uint64, keys []ReadingKey) Update {
+	u := Update{PollTime: pollTime, Readings: make(map[ReadingKey]Reading, len(keys))}
+	results, err := hw.ReadingsByKeys(ctx, keys)
+	if err != nil {
+		u.Missing = keys
+		return u
+	}
+	for _, res := range results {
+		if res.Err != nil {
+			u.Missing = append(u.Missing, res.Key)
+		} else {
+			u.Readings[res.Key] = res.Reading
+		}
+	}
+	return u
+}
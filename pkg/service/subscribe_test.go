@@ -0,0 +1,170 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeReading struct {
+	id    int32
+	value float64
+}
+
+func (r fakeReading) ID() int32         { return r.id }
+func (r fakeReading) TypeI() int32      { return 1 }
+func (r fakeReading) Type() string      { return "Temp" }
+func (r fakeReading) Label() string     { return "CPU" }
+func (r fakeReading) LabelUser() string { return "CPU" }
+func (r fakeReading) Unit() string      { return "°C" }
+func (r fakeReading) Value() float64    { return r.value }
+func (r fakeReading) ValueMin() float64 { return r.value }
+func (r fakeReading) ValueMax() float64 { return r.value }
+func (r fakeReading) ValueAvg() float64 { return r.value }
+
+// pollingService advances its poll time on every call to PollTime
+type pollingService struct {
+	polls atomic.Uint64
+}
+
+func (p *pollingService) PollTime(context.Context) (uint64, error) { return p.polls.Add(1), nil }
+
+func (p *pollingService) Sensors(context.Context) ([]Sensor, error) {
+	return []Sensor{fakeSensor{"100", "CPU"}}, nil
+}
+
+func (p *pollingService) ReadingsForSensorID(_ context.Context, id string) ([]Reading, error) {
+	if id != "100" {
+		return nil, nil
+	}
+	return []Reading{fakeReading{1, float64(p.polls.Load())}, fakeReading{2, 0}}, nil
+}
+
+func (p *pollingService) ReadingsByKeys(ctx context.Context, keys []ReadingKey) ([]ReadingResult, error) {
+	return LookupReadings(keys, func(id string) ([]Reading, error) { return p.ReadingsForSensorID(ctx, id) }), nil
+}
+
+func (p *pollingService) Status(context.Context) (Status, error) {
+	return Status{State: StateActive, PollTime: p.polls.Load()}, nil
+}
+
+func TestCompositeSubscribe(t *testing.T) {
+	c := NewComposite()
+	if err := c.Add("lab", &pollingService{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	keys := []ReadingKey{{"lab/100", 1}, {"lab/100", 3}, {"other/1", 1}}
+	ch, err := c.Subscribe(ctx, keys, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var missing []ReadingKey
+	var last float64
+	for updates := 0; updates < 3; {
+		u, ok := <-ch
+		if !ok {
+			t.Fatal("subscription ended early")
+		}
+		if u.Err != nil {
+			t.Fatal(u.Err)
+		}
+		missing = append(missing, u.Missing...)
+		r, ok := u.Readings[ReadingKey{"lab/100", 1}]
+		if !ok {
+			continue
+		}
+		if len(u.Readings) != 1 {
+			t.Errorf("got %d readings, want only the subscribed one", len(u.Readings))
+		}
+		if r.Value() <= last {
+			t.Errorf("value %v did not advance past %v", r.Value(), last)
+		}
+		last = r.Value()
+		updates++
+	}
+
+	seen := make(map[ReadingKey]bool)
+	for _, k := range missing {
+		seen[k] = true
+	}
+	for _, k := range []ReadingKey{{"lab/100", 3}, {"other/1", 1}} {
+		if !seen[k] {
+			t.Errorf("%v not reported missing", k)
+		}
+	}
+
+	cancel()
+	for range ch {
+	}
+}
+
+// failingSubscriber subscriptions fail right after starting
+type failingSubscriber struct {
+	fakeService
+	subs atomic.Int32
+}
+
+func (f *failingSubscriber) Subscribe(context.Context, []ReadingKey, time.Duration) (<-chan Update, error) {
+	f.subs.Add(1)
+	ch := make(chan Update, 1)
+	ch <- Update{Err: errors.New("connection reset")}
+	close(ch)
+	return ch, nil
+}
+
+func TestCompositeSubscribeFailingSource(t *testing.T) {
+	c := NewComposite()
+	c.ResubscribeInterval = time.Millisecond
+	down := &failingSubscriber{}
+	if err := c.Add("lab", &pollingService{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add("down", down); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ch, err := c.Subscribe(ctx, []ReadingKey{{"lab/100", 1}, {"down/1", 1}}, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the healthy source keeps delivering while the other is retried
+	var labUpdates, downMissing int
+	for labUpdates < 3 || downMissing < 3 {
+		u, ok := <-ch
+		if !ok {
+			t.Fatal("subscription ended early")
+		}
+		if u.Err != nil {
+			t.Fatalf("subscription failed: %v", u.Err)
+		}
+		if _, ok := u.Readings[ReadingKey{"lab/100", 1}]; ok {
+			labUpdates++
+		}
+		for _, k := range u.Missing {
+			if k == (ReadingKey{"down/1", 1}) {
+				downMissing++
+			}
+		}
+	}
+	if n := down.subs.Load(); n < 3 {
+		t.Errorf("failed source subscribed %d times, want it retried", n)
+	}
+	for _, h := range c.Health() {
+		if h.Name == "down" && h.Err == nil {
+			t.Error("failed source reported healthy")
+		}
+	}
+
+	cancel()
+	for range ch {
+	}
+}